@@ -1,17 +1,32 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/handler"
+	bookscache "github.com/recursion-goapi-project/technical-books-search/back/internal/infra/books/cache"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/books/coalesce"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/books/stale"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/cassette"
 	favoritesfs "github.com/recursion-goapi-project/technical-books-search/back/internal/infra/favorites/filestore"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/googlebooks"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/openbd"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/openlibrary"
+	searchesfs "github.com/recursion-goapi-project/technical-books-search/back/internal/infra/searches/filestore"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/taxonomy"
 	tsundokofs "github.com/recursion-goapi-project/technical-books-search/back/internal/infra/tsundoku/filestore"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/server"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/favorites"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/library"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/searches"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/tsundoku"
 )
 
@@ -24,10 +39,50 @@ func main() {
 	}
 
 	// Setup Google Books API client and service
-	client := googlebooks.NewClient(baseURL, apiKey)
+	cassettes := buildCassettes()
+	google := buildGoogleBooksClient(baseURL, apiKey, cassettes)
+	healthChecks := []handler.HealthCheck{{Name: "googlebooks", Report: func() any {
+		return map[string]string{"Breaker": string(google.BreakerState())}
+	}}}
+	client, cacheChecks := buildBooksClient(buildProviders(buildStaleStore(google), cassettes))
+	healthChecks = append(healthChecks, cacheChecks...)
 	bookService := books.NewService(client)
-	searchHandler := handler.NewSearchBooksHandler(bookService)
-
+	bookService.WithPaging(books.PagingOptions{
+		UpstreamPageSize: envInt("BOOKS_UPSTREAM_PAGE_SIZE", 10),
+		MaxPageSize:      envInt("BOOKS_MAX_PAGE_SIZE", 40),
+		Parallelism:      envInt("BOOKS_UPSTREAM_PARALLELISM", 4),
+		MaxFacetWindow:   envInt("BOOKS_MAX_FACET_WINDOW", 200),
+	})
+	if envBool("OPENBD_ENABLED", true) {
+		openBD := openbd.NewClient(os.Getenv("OPENBD_BASE_URL"))
+		if cassettes != nil {
+			openBD.WithTransport(cassettes)
+		}
+		openBD.WithCache(envDuration("OPENBD_CACHE_TTL", openbd.DefaultCacheTTL), envInt("OPENBD_CACHE_MAX_ENTRIES", openbd.DefaultCacheMaxEntries))
+		bookService.WithMetadataProvider("openbd", openBD)
+	}
+	kana, err := books.ParseKanaFold(os.Getenv("BOOKS_KANA_FOLD"))
+	if err != nil {
+		log.Fatalf("invalid BOOKS_KANA_FOLD: %v", err)
+	}
+	bookService.WithKanaFolding(kana)
+	bookService.WithCursorCodec(books.NewCursorCodec(cursorSecret()))
+	minPages := envInt("BOOKS_FILTER_MIN_PAGES", 0)
+	requireDescription := envBool("BOOKS_FILTER_REQUIRE_DESCRIPTION", false)
+	bookService.WithDefaultFilters(books.FilterOptions{
+		AllowCategories:    envList("BOOKS_FILTER_ALLOW_CATEGORIES"),
+		DenyCategories:     envList("BOOKS_FILTER_DENY_CATEGORIES"),
+		MinPageCount:       &minPages,
+		RequireDescription: &requireDescription,
+		ExcludeKeywords:    envList("BOOKS_FILTER_EXCLUDE_KEYWORDS"),
+	})
+	if path := os.Getenv("TAGS_CONFIG_PATH"); path != "" {
+		t, err := taxonomy.LoadFile(path)
+		if err != nil {
+			log.Fatalf("failed to load tag taxonomy: %v", err)
+		}
+		bookService.WithTaxonomy(t)
+	}
 	// Setup Tsundoku (reading list) service
 	tsundokuRepo := buildTsundokuRepository()
 	tsundokuService := tsundoku.NewService(tsundokuRepo)
@@ -38,8 +93,27 @@ func main() {
 	favoritesService := favorites.NewService(favoritesRepo)
 	favoritesHandler := handler.NewFavoritesHandler(favoritesService)
 
+	// Search results are annotated with the favorites / tsundoku state on request
+	libraryService := library.NewService(favoritesService, tsundokuService)
+
+	// Setup search history and saved searches
+	searchesService := searches.NewService(buildSearchesRepository(), bookService)
+	searchesService.WithLibrary(libraryService)
+	searchesService.WithHistoryLimit(envInt("SEARCH_HISTORY_LIMIT", searches.DefaultHistoryLimit))
+	searchesHandler := handler.NewSearchesHandler(searchesService, bookService)
+	if tick := envDuration("SAVED_SEARCH_SCHEDULER_INTERVAL", time.Minute); tick > 0 {
+		go searchesService.RunScheduler(context.Background(), tick)
+	}
+
+	booksHandlers := server.BooksHandlers{
+		Search:     handler.NewSearchBooksHandler(bookService, libraryService, searchesService),
+		Get:        handler.NewGetBookHandler(bookService),
+		LookupISBN: handler.NewLookupISBNHandler(bookService),
+		ListTags:   handler.NewListTagsHandler(bookService),
+	}
+
 	// Initialize HTTP router and start server
-	r := server.NewRouter(searchHandler, tsundokuHandler, favoritesHandler)
+	r := server.NewRouter(booksHandlers, tsundokuHandler, favoritesHandler, searchesHandler, handler.NewHealthCheckHandler(healthChecks...))
 	port := ":8080"
 	log.Printf("Server is starting on port %s", port)
 	if err := http.ListenAndServe(port, r); err != nil {
@@ -47,6 +121,143 @@ func main() {
 	}
 }
 
+// buildCassettes returns the record / replay transport shared by every upstream client
+// (Google Books, Open Library and openBD), or nil when BOOKS_CASSETTE_MODE is not set.
+func buildCassettes() http.RoundTripper {
+	raw := os.Getenv("BOOKS_CASSETTE_MODE")
+	if raw == "" {
+		return nil
+	}
+	mode, err := cassette.ParseMode(raw)
+	if err != nil {
+		log.Fatalf("invalid BOOKS_CASSETTE_MODE: %v", err)
+	}
+	dir := os.Getenv("BOOKS_CASSETTE_DIR")
+	if dir == "" {
+		dir = "data/cassettes"
+	}
+	transport, err := cassette.New(dir, mode, nil)
+	if err != nil {
+		log.Fatalf("failed to initialize cassettes: %v", err)
+	}
+	log.Printf("upstream cassette mode %s (dir=%s)", mode, dir)
+	return transport
+}
+
+func buildGoogleBooksClient(baseURL, apiKey string, cassettes http.RoundTripper) *googlebooks.Client {
+	client := googlebooks.NewClient(baseURL, apiKey)
+	if cassettes != nil {
+		client.WithTransport(cassettes)
+	}
+
+	retry := googlebooks.DefaultRetryPolicy()
+	retry.MaxAttempts = envInt("BOOKS_RETRY_MAX_ATTEMPTS", retry.MaxAttempts)
+	retry.BaseDelay = envDuration("BOOKS_RETRY_BASE_DELAY", retry.BaseDelay)
+	retry.MaxDelay = envDuration("BOOKS_RETRY_MAX_DELAY", retry.MaxDelay)
+	client.WithRetryPolicy(retry)
+
+	threshold := envInt("BOOKS_BREAKER_FAILURE_THRESHOLD", 5)
+	if threshold <= 0 {
+		log.Printf("googlebooks circuit breaker is disabled")
+		client.WithBreaker(nil)
+		return client
+	}
+	client.WithBreaker(googlebooks.NewBreaker(googlebooks.BreakerSettings{
+		FailureThreshold: threshold,
+		Cooldown:         envDuration("BOOKS_BREAKER_COOLDOWN", 30*time.Second),
+	}))
+	return client
+}
+
+// buildStaleStore keeps recent Google Books search responses on disk so they can be served
+// while Google Books is down or the quota is exhausted.
+func buildStaleStore(client books.ExternalClient) books.ExternalClient {
+	if !envBool("BOOKS_STALE_ENABLED", true) {
+		return client
+	}
+	dir := os.Getenv("BOOKS_STALE_DIR")
+	if dir == "" {
+		dir = "data/search-stale"
+	}
+	store, err := stale.New(client, stale.Options{
+		Dir:        dir,
+		MaxAge:     envDuration("BOOKS_STALE_MAX_AGE", 24*time.Hour),
+		MaxEntries: envInt("BOOKS_STALE_MAX_ENTRIES", stale.DefaultMaxEntries),
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize stale search store: %v", err)
+	}
+	if n, err := store.Prune(); err != nil {
+		log.Printf("failed to prune stale search store: %v", err)
+	} else if n > 0 {
+		log.Printf("pruned %d stale search responses", n)
+	}
+	if tick := envDuration("BOOKS_STALE_PRUNE_INTERVAL", 10*time.Minute); tick > 0 {
+		go store.RunPruner(context.Background(), tick)
+	}
+	return store
+}
+
+// buildProviders federates Google Books with the optional secondary providers.
+// With only Google Books enabled the client is returned as is.
+func buildProviders(google books.ExternalClient, cassettes http.RoundTripper) books.ExternalClient {
+	timeout := envDuration("BOOKS_PROVIDER_TIMEOUT", 0)
+	providers := []books.Provider{{Name: "googlebooks", Client: google, Timeout: timeout}}
+	if envBool("OPENLIBRARY_ENABLED", false) {
+		openLibrary := openlibrary.NewClient(os.Getenv("OPENLIBRARY_BASE_URL"))
+		if cassettes != nil {
+			openLibrary.WithTransport(cassettes)
+		}
+		providers = append(providers, books.Provider{
+			Name:     "openlibrary",
+			Client:   openLibrary,
+			Timeout:  envDuration("OPENLIBRARY_TIMEOUT", 4*time.Second),
+			IDPrefix: openlibrary.IDPrefix,
+		})
+	}
+	if len(providers) == 1 {
+		return google
+	}
+	federation := books.NewFederation(providers...)
+	log.Printf("federated book search across %s", strings.Join(federation.Providers(), ", "))
+	return federation
+}
+
+// buildBooksClient wraps the client with request coalescing and the response cache.
+// Their counters are returned as health checks.
+func buildBooksClient(client books.ExternalClient) (books.ExternalClient, []handler.HealthCheck) {
+	var checks []handler.HealthCheck
+	if envBool("BOOKS_COALESCE_ENABLED", true) {
+		coalesced := coalesce.New(client)
+		checks = append(checks, handler.HealthCheck{Name: "coalesce", Report: func() any { return coalesced.Stats() }})
+		client = coalesced
+	}
+	if !envBool("BOOKS_CACHE_ENABLED", true) {
+		log.Printf("books response cache is disabled")
+		return client, checks
+	}
+	opts := bookscache.Options{
+		TTL:        envDuration("BOOKS_CACHE_TTL", 10*time.Minute),
+		MaxEntries: envInt("BOOKS_CACHE_MAX_ENTRIES", 256),
+	}
+	log.Printf("books response cache enabled (ttl=%s, maxEntries=%d)", opts.TTL, opts.MaxEntries)
+	cached := bookscache.New(client, opts)
+	checks = append(checks, handler.HealthCheck{Name: "cache", Report: func() any { return cached.Stats() }})
+	return cached, checks
+}
+
+func cursorSecret() []byte {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("failed to generate cursor secret: %v", err)
+	}
+	log.Printf("CURSOR_SECRET is not set; search cursors will not survive a restart")
+	return secret
+}
+
 func buildTsundokuRepository() tsundoku.Repository {
 	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
 	case "", "file":
@@ -82,3 +293,67 @@ func buildFavoritesRepository() favorites.Repository {
 	}
 	return nil
 }
+
+func buildSearchesRepository() searches.Repository {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "file":
+		path := os.Getenv("SEARCHES_STORE_PATH")
+		if path == "" {
+			path = "data/searches.json"
+		}
+		repo, err := searchesfs.New(path)
+		if err != nil {
+			log.Fatalf("failed to initialize searches file repository: %v", err)
+		}
+		return repo
+	default:
+		log.Fatalf("unsupported STORAGE_BACKEND: %s", backend)
+	}
+	return nil
+}
+
+func envBool(key string, fallback bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return v
+}
+
+func envInt(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return v
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return v
+}
+
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
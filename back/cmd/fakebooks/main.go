@@ -0,0 +1,54 @@
+// Command fakebooks serves a fake Google Books volumes API from a fixture corpus for
+// offline development. Point the API at it with
+// BOOKS_BASE_URL=http://localhost:8081/books/v1/volumes.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/googlebooks/fakeserver"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	corpus := flag.String("corpus", "", "JSON file with Google Books volumes (defaults to the built-in fixture)")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	failStatus := flag.Int("fail-status", http.StatusServiceUnavailable, "status of injected failures (429 or 5xx)")
+	failRate := flag.Float64("fail-rate", 0, "probability of failing a request, from 0 to 1")
+	retryAfter := flag.Duration("retry-after", 0, "Retry-After sent with injected failures")
+	flag.Parse()
+
+	opts := fakeserver.Options{
+		Latency: *latency,
+		Fault:   fakeserver.Fault{Status: *failStatus, Rate: *failRate, RetryAfter: *retryAfter},
+	}
+	if *corpus != "" {
+		b, err := os.ReadFile(*corpus)
+		if err != nil {
+			log.Fatalf("failed to read corpus: %v", err)
+		}
+		opts.Corpus = b
+	}
+	srv, err := fakeserver.New(opts)
+	if err != nil {
+		log.Fatalf("failed to build fake server: %v", err)
+	}
+
+	log.Printf("fake Google Books is listening on %s (base URL http://localhost%s/books/v1/volumes)", *addr, *addr)
+	server := &http.Server{Addr: *addr, Handler: logRequests(srv), ReadHeaderTimeout: 5 * time.Second}
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("fake server failed: %v", err)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s (%s)", r.Method, r.URL.RequestURI(), time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -3,3 +3,5 @@ module github.com/recursion-goapi-project/technical-books-search/back
 go 1.25.3
 
 require github.com/go-chi/chi/v5 v5.2.3
+
+require golang.org/x/text v0.31.0
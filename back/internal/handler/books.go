@@ -2,54 +2,328 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/library"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/searches"
 )
 
-func NewSearchBooksHandler(service *books.Service) http.HandlerFunc {
+// annotatedBook is a search result book together with the caller's library state.
+type annotatedBook struct {
+	books.Book
+	Library *library.State `json:"Library,omitempty"`
+}
+
+// annotatedResult replaces the items of a search result with annotated books.
+type annotatedResult struct {
+	books.SearchResult
+	Items []annotatedBook
+}
+
+// NewSearchBooksHandler serves book searches. lib and history are optional; without lib
+// the library annotation and excludeLibrary are ignored, without history nothing is recorded.
+func NewSearchBooksHandler(service *books.Service, lib *library.Service, history *searches.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query()
-		// Google Books API は実質 10 件固定のため、maxResults は常に 10 を使用する。
-		const pageSize = 10
 
-		page := 1
-		if v := q.Get("page"); v != "" {
-			if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
-				page = parsed
+		var (
+			params books.SearchParams
+			err    error
+		)
+		// cursor があれば最優先。検索条件はカーソルに含まれているため他のパラメータは無視する
+		if token := q.Get("cursor"); token != "" {
+			params, err = service.ParseCursor(token)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_cursor", "cursor is invalid or expired")
+				return
+			}
+		} else {
+			params, err = parseSearchParams(q, service.MaxPageSize(), service.MaxFacetWindow())
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+				return
 			}
 		}
 
-		start := 0
-		if v := q.Get("startIndex"); v != "" {
-			if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
-				start = parsed
+		withLibrary := false
+		if v := q.Get("library"); v != "" {
+			if withLibrary, err = strconv.ParseBool(v); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_request", "library must be true or false")
+				return
 			}
-		} else {
-			start = (page - 1) * pageSize
 		}
-		// 最小バリデーション: q が空なら 400
-		if strings.TrimSpace(q.Get("q")) == "" {
-			http.Error(w, "q required", http.StatusBadRequest)
-			return
-		}
-
-		params := books.SearchParams{
-			Query:      q.Get("q"),
-			StartIndex: start,
-			MaxResults: pageSize,
-			OrderBy:    q.Get("orderBy"),
-			Lang:       q.Get("lang"),
+		var snap *library.Snapshot
+		if lib != nil && (withLibrary || len(params.ExcludeLibrary) > 0) {
+			if snap, err = lib.Snapshot(r.Context()); err != nil {
+				writeError(w, http.StatusInternalServerError, "internal_error", "failed to load the library")
+				return
+			}
+			if len(params.ExcludeLibrary) > 0 {
+				params.ExtraFilters = append(params.ExtraFilters, snap.Filter(params.ExcludeLibrary))
+			}
 		}
 
 		res, err := service.Search(r.Context(), params)
 		if err != nil {
-			http.Error(w, "upstream error", http.StatusBadGateway)
+			switch {
+			case errors.Is(err, books.ErrUnknownTag):
+				writeError(w, http.StatusBadRequest, "unknown_tag", err.Error())
+				return
+			case errors.Is(err, books.ErrInvalidISBN):
+				writeError(w, http.StatusBadRequest, "invalid_isbn", err.Error())
+				return
+			}
+			writeUpstreamError(w, err)
+			return
+		}
+		if res.Stale {
+			// 上流の障害時に保存済みの応答を返したことを HTTP のキャッシュ用ヘッダーでも示す
+			w.Header().Set("Warning", `110 - "Response is Stale"`)
+			w.Header().Set("Age", strconv.Itoa(res.StaleAgeSeconds))
+		}
+		// 続きのページ（カーソル・startIndex 指定）は同じ検索なので履歴には残さない
+		if history != nil && params.StartIndex == 0 && len(params.SeenIDs) == 0 {
+			if err := history.RecordSearch(r.Context(), params, res.TotalItems); err != nil {
+				log.Printf("failed to record search history: %v", err)
+			}
+		}
+		if withLibrary && snap != nil {
+			writeJSON(w, http.StatusOK, annotate(res, snap))
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(res)
 	}
 }
+
+// annotate attaches the library state of every book in res.
+func annotate(res books.SearchResult, snap *library.Snapshot) annotatedResult {
+	out := annotatedResult{SearchResult: res, Items: make([]annotatedBook, len(res.Items))}
+	for i, b := range res.Items {
+		out.Items[i] = annotatedBook{Book: b, Library: snap.State(b)}
+	}
+	return out
+}
+
+// parseSearchParams builds search parameters from the classic (non-cursor) query parameters.
+func parseSearchParams(q url.Values, maxPageSize, maxFacetWindow int) (books.SearchParams, error) {
+	// 1ページの件数。上流は 10 件ずつ取得し、サービス側で連結する。
+	pageSize := 10
+	if v := q.Get("maxResults"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			return books.SearchParams{}, fmt.Errorf("maxResults must be between 1 and %d", maxPageSize)
+		}
+		pageSize = parsed
+	}
+
+	page := 1
+	if v := q.Get("page"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			return books.SearchParams{}, fmt.Errorf("page must be a positive integer")
+		}
+		page = parsed
+	}
+
+	start := (page - 1) * pageSize
+	if v := q.Get("startIndex"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			return books.SearchParams{}, fmt.Errorf("startIndex must be a non-negative integer")
+		}
+		start = parsed
+	}
+
+	params := books.SearchParams{
+		Query:       q.Get("q"),
+		StartIndex:  start,
+		MaxResults:  pageSize,
+		OrderBy:     q.Get("orderBy"),
+		Tags:        splitList(q, "tags"),
+		Title:       q.Get("title"),
+		Author:      q.Get("author"),
+		Publisher:   q.Get("publisher"),
+		Subject:     q.Get("subject"),
+		ISBN:        q.Get("isbn"),
+		ExactPhrase: q.Get("phrase"),
+		Exclude:     splitList(q, "exclude"),
+	}
+	// lang=ja,en のように複数指定された場合は言語ごとに並列に検索する
+	switch langs := splitList(q, "lang"); {
+	case len(langs) > books.MaxLanguages:
+		return books.SearchParams{}, fmt.Errorf("lang accepts at most %d languages", books.MaxLanguages)
+	case len(langs) == 1:
+		params.Lang = langs[0]
+	case len(langs) > 1:
+		params.Langs = langs
+	}
+	if v := q.Get("facetWindow"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxFacetWindow {
+			return books.SearchParams{}, fmt.Errorf("facetWindow must be between 0 and %d", maxFacetWindow)
+		}
+		params.FacetWindow = n
+	}
+	if v := q.Get("filter"); v != "" {
+		if !books.ValidEbookFilter(v) {
+			return books.SearchParams{}, fmt.Errorf("filter must be one of partial, full, free-ebooks, paid-ebooks, ebooks")
+		}
+		params.EbookFilter = v
+	}
+	if v := q.Get("download"); v != "" {
+		if v != books.DownloadEpub {
+			return books.SearchParams{}, fmt.Errorf("download must be epub")
+		}
+		params.Download = v
+	}
+	exclusions, err := library.ParseExclusions(splitList(q, "excludeLibrary"))
+	if err != nil {
+		return books.SearchParams{}, fmt.Errorf("excludeLibrary must list favorites, stacked, reading or done")
+	}
+	params.ExcludeLibrary = exclusions
+	switch v := q.Get("editions"); v {
+	case "", "group":
+	case "all":
+		params.KeepEditions = true
+	default:
+		return books.SearchParams{}, fmt.Errorf("editions must be group or all")
+	}
+	// 最小バリデーション: 検索条件が何もなければ 400
+	if !params.HasCriteria() {
+		return books.SearchParams{}, fmt.Errorf("q, tags or a structured field is required")
+	}
+	for _, f := range []struct {
+		key string
+		dst **books.Date
+	}{
+		{"publishedAfter", &params.PublishedAfter},
+		{"publishedBefore", &params.PublishedBefore},
+	} {
+		if v := q.Get(f.key); v != "" {
+			d, err := books.ParseDate(v)
+			if err != nil {
+				return books.SearchParams{}, fmt.Errorf("%s must be YYYY, YYYY-MM or YYYY-MM-DD", f.key)
+			}
+			*f.dst = &d
+		}
+	}
+	filters, err := parseFilterOptions(q)
+	if err != nil {
+		return books.SearchParams{}, err
+	}
+	params.Filters = filters
+	return params, nil
+}
+
+// NewLookupISBNHandler resolves a single book by its ISBN-10 or ISBN-13.
+func NewLookupISBNHandler(service *books.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		book, err := service.LookupISBN(r.Context(), chi.URLParam(r, "isbn"))
+		if err != nil {
+			switch {
+			case errors.Is(err, books.ErrInvalidISBN):
+				writeError(w, http.StatusBadRequest, "invalid_isbn", err.Error())
+			case errors.Is(err, books.ErrNotFound):
+				writeError(w, http.StatusNotFound, "not_found", err.Error())
+			default:
+				writeUpstreamError(w, err)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, book)
+	}
+}
+
+// NewGetBookHandler returns the full record of a single volume by its ID.
+func NewGetBookHandler(service *books.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		book, err := service.Get(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			if errors.Is(err, books.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "not_found", "book not found")
+				return
+			}
+			writeUpstreamError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, book)
+	}
+}
+
+// NewListTagsHandler returns the technology tag taxonomy usable with the tags= search parameter.
+func NewListTagsHandler(service *books.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, service.Tags())
+	}
+}
+
+// parseFilterOptions reads per-request result filters; nil means "use the server defaults".
+func parseFilterOptions(q url.Values) (*books.FilterOptions, error) {
+	var (
+		opts books.FilterOptions
+		set  bool
+	)
+	if v := q.Get("applyFilters"); v != "" {
+		apply, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid applyFilters: %q", v)
+		}
+		opts.Disabled = !apply
+		set = true
+	}
+	if v := splitList(q, "allowCategories"); v != nil {
+		opts.AllowCategories = v
+		set = true
+	}
+	if v := splitList(q, "denyCategories"); v != nil {
+		opts.DenyCategories = v
+		set = true
+	}
+	if v := q.Get("minPages"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid minPages: %q", v)
+		}
+		opts.MinPageCount = &n
+		set = true
+	}
+	if v := q.Get("requireDescription"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requireDescription: %q", v)
+		}
+		opts.RequireDescription = &required
+		set = true
+	}
+	if v := splitList(q, "excludeKeywords"); v != nil {
+		opts.ExcludeKeywords = v
+		set = true
+	}
+	if !set {
+		return nil, nil
+	}
+	return &opts, nil
+}
+
+// splitList reads a list parameter given either comma separated or repeated.
+func splitList(q url.Values, key string) []string {
+	var out []string
+	for _, raw := range q[key] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
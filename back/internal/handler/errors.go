@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// statusClientClosedRequest is the de-facto status for requests the client abandoned.
+const statusClientClosedRequest = 499
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code              string `json:"code"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
+}
+
+// writeError writes a structured JSON error response.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+// writeUpstreamError maps errors returned by the books service to HTTP responses.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	status, code, message := http.StatusBadGateway, "upstream_error", "upstream error"
+	switch {
+	case errors.Is(err, books.ErrCanceled), errors.Is(err, context.Canceled):
+		status, code, message = statusClientClosedRequest, "canceled", "request canceled"
+	case errors.Is(err, books.ErrRateLimited):
+		status, code, message = http.StatusTooManyRequests, "rate_limited", "upstream rate limit exceeded"
+	case errors.Is(err, books.ErrUpstreamTimeout), errors.Is(err, context.DeadlineExceeded):
+		status, code, message = http.StatusGatewayTimeout, "upstream_timeout", "upstream timed out"
+	case errors.Is(err, books.ErrUpstreamUnauthorized):
+		status, code, message = http.StatusBadGateway, "upstream_unauthorized", "upstream rejected the API credentials"
+	case errors.Is(err, books.ErrBadUpstreamPayload):
+		status, code, message = http.StatusBadGateway, "bad_upstream_payload", "upstream returned a malformed response"
+	case errors.Is(err, books.ErrUpstreamNotRecorded):
+		status, code, message = http.StatusBadGateway, "not_recorded", "upstream request is not in the cassettes being replayed"
+	case errors.Is(err, books.ErrUpstreamUnavailable):
+		status, code, message = http.StatusServiceUnavailable, "upstream_unavailable", "upstream is temporarily unavailable"
+	}
+
+	body := errorBody{Error: errorDetail{Code: code, Message: message}}
+	if wait := books.RetryAfter(err); wait > 0 {
+		secs := int(math.Ceil(wait.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+		body.Error.RetryAfterSeconds = secs
+	}
+	writeJSON(w, status, body)
+}
@@ -5,8 +5,22 @@ import (
 	"net/http"
 )
 
-func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+// HealthCheck reports the current state of one component under Name in GET /health.
+type HealthCheck struct {
+	Name   string
+	Report func() any
+}
+
+// NewHealthCheckHandler reports "ok" together with the state of each check,
+// e.g. the Google Books circuit breaker and the response cache counters.
+func NewHealthCheckHandler(checks ...HealthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]any{"status": "ok"}
+		for _, c := range checks {
+			body[c.Name] = c.Report()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(body)
+	}
 }
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/searches"
+)
+
+// SearchesHandler exposes HTTP handlers for search history and saved searches.
+type SearchesHandler struct {
+	service *searches.Service
+	books   *books.Service
+}
+
+// NewSearchesHandler creates a handler set bound to the services. The books service is
+// used to validate saved search queries with the same limits as the search endpoint.
+func NewSearchesHandler(service *searches.Service, booksService *books.Service) *SearchesHandler {
+	return &SearchesHandler{service: service, books: booksService}
+}
+
+// Register wires the handler to the provided router.
+func (h *SearchesHandler) Register(r chi.Router) {
+	r.Get("/history", h.History)
+	r.Delete("/history", h.ClearHistory)
+	r.Get("/saved", h.ListSaved)
+	r.Post("/saved", h.Save)
+	r.Get("/saved/{id}", h.GetSaved)
+	r.Delete("/saved/{id}", h.DeleteSaved)
+	r.Post("/saved/{id}/run", h.Run)
+}
+
+type saveSearchRequest struct {
+	Name string `json:"Name"`
+	// Query is a search endpoint query string such as "q=golang&orderBy=newest".
+	Query string `json:"Query"`
+	// HistoryID saves a search from the history instead of Query.
+	HistoryID string `json:"HistoryID"`
+	// RunEvery schedules the search as a Go duration (e.g. "24h").
+	RunEvery string `json:"RunEvery"`
+}
+
+// History returns the recent searches, newest first.
+func (h *SearchesHandler) History(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	entries, err := h.service.History(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load search history")
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// ClearHistory removes the whole search history.
+func (h *SearchesHandler) ClearHistory(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.ClearHistory(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to clear search history")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSaved returns all saved searches.
+func (h *SearchesHandler) ListSaved(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.ListSaved(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "failed to load saved searches")
+		return
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// Save stores a search under a name.
+func (h *SearchesHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var req saveSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid JSON body")
+		return
+	}
+	defer r.Body.Close()
+
+	var params books.SearchParams
+	switch {
+	case req.HistoryID != "":
+		entry, err := h.service.HistoryEntry(r.Context(), req.HistoryID)
+		if err != nil {
+			h.writeServiceError(w, err)
+			return
+		}
+		params = entry.Params
+	default:
+		q, err := url.ParseQuery(strings.TrimPrefix(req.Query, "?"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Query must be a URL query string")
+			return
+		}
+		params, err = parseSearchParams(q, h.books.MaxPageSize(), h.books.MaxFacetWindow())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+	}
+
+	var every time.Duration
+	if req.RunEvery != "" {
+		d, err := time.ParseDuration(req.RunEvery)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "RunEvery must be a duration such as 24h")
+			return
+		}
+		every = d
+	}
+
+	saved, err := h.service.Save(r.Context(), searches.SaveParams{Name: req.Name, Params: params, RunEvery: every})
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, saved)
+}
+
+// GetSaved returns one saved search.
+func (h *SearchesHandler) GetSaved(w http.ResponseWriter, r *http.Request) {
+	saved, err := h.service.GetSaved(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// DeleteSaved removes a saved search.
+func (h *SearchesHandler) DeleteSaved(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSaved(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Run executes a saved search and returns only the books earlier runs did not return.
+func (h *SearchesHandler) Run(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.Run(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *SearchesHandler) writeServiceError(w http.ResponseWriter, err error) {
+	var upstream *books.UpstreamError
+	switch {
+	case errors.Is(err, searches.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, searches.ErrInvalidInput):
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, searches.ErrAlreadyExists):
+		writeError(w, http.StatusConflict, "already_exists", err.Error())
+	case errors.Is(err, books.ErrUnknownTag):
+		writeError(w, http.StatusBadRequest, "unknown_tag", err.Error())
+	case errors.Is(err, books.ErrInvalidISBN):
+		writeError(w, http.StatusBadRequest, "invalid_isbn", err.Error())
+	case errors.As(err, &upstream), errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeUpstreamError(w, err)
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal error")
+	}
+}
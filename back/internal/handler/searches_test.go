@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/searches"
+)
+
+func TestSearchesWriteServiceError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"not found", searches.ErrNotFound, http.StatusNotFound, "not_found"},
+		{"invalid input", fmt.Errorf("%w: name", searches.ErrInvalidInput), http.StatusBadRequest, "invalid_request"},
+		{"unknown tag", fmt.Errorf("%w: cobol", books.ErrUnknownTag), http.StatusBadRequest, "unknown_tag"},
+		{"not recorded", &books.UpstreamError{Kind: books.ErrUpstreamNotRecorded, Err: errors.New("miss")}, http.StatusBadGateway, "not_recorded"},
+		{"rate limited", fmt.Errorf("run: %w", &books.UpstreamError{Kind: books.ErrRateLimited, Err: errors.New("429")}), http.StatusTooManyRequests, "rate_limited"},
+		{"unavailable", &books.UpstreamError{Kind: books.ErrUpstreamUnavailable, Err: errors.New("down")}, http.StatusServiceUnavailable, "upstream_unavailable"},
+		{"internal", errors.New("disk full"), http.StatusInternalServerError, "internal_error"},
+	}
+	h := &SearchesHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.writeServiceError(rec, tt.err)
+			var body errorBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantStatus || body.Error.Code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", rec.Code, body.Error.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
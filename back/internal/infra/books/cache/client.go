@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// Options configures the response cache.
+type Options struct {
+	// TTL is how long a cached response stays fresh.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached responses; the least recently used entry is evicted first.
+	MaxEntries int
+}
+
+// Stats is a snapshot of the cache counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// Client caches search and volume responses of another books.ExternalClient in memory.
+type Client struct {
+	next books.ExternalClient
+	ttl  time.Duration
+	max  int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	stats   Stats
+}
+
+type entry struct {
+	key       string
+	value     any // books.SearchResult or books.Book
+	expiresAt time.Time
+}
+
+// New wraps next with a TTL + LRU response cache.
+func New(next books.ExternalClient, opts Options) *Client {
+	if opts.TTL <= 0 {
+		opts.TTL = 10 * time.Minute
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 256
+	}
+	return &Client{
+		next:    next,
+		ttl:     opts.TTL,
+		max:     opts.MaxEntries,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// WithNow overrides the now function (primarily for testing).
+func (c *Client) WithNow(fn func() time.Time) {
+	if fn != nil {
+		c.now = fn
+	}
+}
+
+// Search returns a cached response when available, otherwise delegates to the wrapped client.
+func (c *Client) Search(ctx context.Context, params books.SearchParams) (books.SearchResult, error) {
+	key := "search:" + params.Key()
+	if v, ok := c.lookup(key); ok {
+		res := v.(books.SearchResult)
+		res.Items = append([]books.Book(nil), res.Items...)
+		return res, nil
+	}
+
+	res, err := c.next.Search(ctx, params)
+	if err != nil {
+		return books.SearchResult{}, err
+	}
+	if len(res.ProviderErrors) > 0 || res.Stale {
+		// Partial or stale results should not hide the failed upstream for a whole TTL.
+		return res, nil
+	}
+	c.store(key, res)
+	return res, nil
+}
+
+// Get returns a cached volume when available, otherwise delegates to the wrapped client.
+func (c *Client) Get(ctx context.Context, id string) (books.Book, error) {
+	key := "volume:" + id
+	if v, ok := c.lookup(key); ok {
+		return v.(books.Book), nil
+	}
+
+	book, err := c.next.Get(ctx, id)
+	if err != nil {
+		return books.Book{}, err
+	}
+	c.store(key, book)
+	return book, nil
+}
+
+// Stats returns the current cache counters.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st := c.stats
+	st.Entries = c.order.Len()
+	return st
+}
+
+func (c *Client) lookup(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+	return e.value, true
+}
+
+func (c *Client) store(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry)
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+		c.stats.Evictions++
+	}
+}
+
+var _ books.ExternalClient = (*Client)(nil)
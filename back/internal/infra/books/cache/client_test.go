@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// countingClient counts upstream calls and returns result for every search.
+type countingClient struct {
+	calls  int
+	result books.SearchResult
+}
+
+func (c *countingClient) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	c.calls++
+	res := c.result
+	res.Items = []books.Book{{ID: p.Query}}
+	return res, nil
+}
+
+func (c *countingClient) Get(ctx context.Context, id string) (books.Book, error) {
+	c.calls++
+	return books.Book{ID: id}, nil
+}
+
+func TestSearchIsCachedUntilTTL(t *testing.T) {
+	up := &countingClient{}
+	c := New(up, Options{TTL: time.Minute})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.WithNow(func() time.Time { return now })
+
+	params := books.SearchParams{Query: "golang"}
+	for i := 0; i < 3; i++ {
+		if _, err := c.Search(context.Background(), params); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if up.calls != 1 {
+		t.Fatalf("upstream calls = %d, want 1", up.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := c.Search(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	if up.calls != 2 {
+		t.Fatalf("expired entry was served; upstream calls = %d, want 2", up.calls)
+	}
+	if st := c.Stats(); st.Hits != 2 || st.Misses != 2 || st.Entries != 1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestLeastRecentlyUsedIsEvicted(t *testing.T) {
+	up := &countingClient{}
+	c := New(up, Options{MaxEntries: 2})
+	search := func(q string) {
+		t.Helper()
+		if _, err := c.Search(context.Background(), books.SearchParams{Query: q}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search("a")
+	search("b")
+	search("a") // a is now the most recently used
+	search("c") // evicts b
+	search("a")
+	if up.calls != 3 {
+		t.Fatalf("upstream calls = %d, want 3", up.calls)
+	}
+	search("b")
+	if up.calls != 4 {
+		t.Fatalf("evicted entry was served; upstream calls = %d, want 4", up.calls)
+	}
+	if st := c.Stats(); st.Evictions != 2 || st.Entries != 2 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestPartialAndStaleResultsAreNotCached(t *testing.T) {
+	for name, result := range map[string]books.SearchResult{
+		"partial": {ProviderErrors: map[string]string{"openlibrary": "timeout"}},
+		"stale":   {Stale: true, StaleAgeSeconds: 60},
+	} {
+		t.Run(name, func(t *testing.T) {
+			up := &countingClient{result: result}
+			c := New(up, Options{})
+			for i := 0; i < 2; i++ {
+				if _, err := c.Search(context.Background(), books.SearchParams{Query: "golang"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if up.calls != 2 {
+				t.Fatalf("upstream calls = %d, want 2", up.calls)
+			}
+		})
+	}
+}
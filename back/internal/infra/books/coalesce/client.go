@@ -0,0 +1,128 @@
+package coalesce
+
+import (
+	"context"
+	"sync"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// Stats is a snapshot of the coalescing counters.
+type Stats struct {
+	// Upstream counts calls actually forwarded to the wrapped client.
+	Upstream uint64
+	// Shared counts callers that joined an in-flight call instead of starting one.
+	Shared uint64
+}
+
+// Client collapses concurrent identical searches into a single upstream call.
+type Client struct {
+	next books.ExternalClient
+
+	mu    sync.Mutex
+	calls map[string]*call
+	stats Stats
+}
+
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	value   any // books.SearchResult or books.Book
+	err     error
+}
+
+// New wraps next so that identical in-flight searches share one upstream request.
+func New(next books.ExternalClient) *Client {
+	return &Client{
+		next:  next,
+		calls: make(map[string]*call),
+	}
+}
+
+// Search joins an in-flight call for the same parameters or starts a new one.
+// Each caller waits on its own context; the upstream call is canceled only
+// once every waiter has given up.
+func (c *Client) Search(ctx context.Context, params books.SearchParams) (books.SearchResult, error) {
+	v, err := c.do(ctx, "search:"+params.Key(), func(ctx context.Context) (any, error) {
+		return c.next.Search(ctx, params)
+	})
+	if err != nil {
+		return books.SearchResult{}, err
+	}
+	res := v.(books.SearchResult)
+	res.Items = append([]books.Book(nil), res.Items...)
+	return res, nil
+}
+
+// Get joins an in-flight lookup for the same volume ID or starts a new one.
+func (c *Client) Get(ctx context.Context, id string) (books.Book, error) {
+	v, err := c.do(ctx, "volume:"+id, func(ctx context.Context) (any, error) {
+		return c.next.Get(ctx, id)
+	})
+	if err != nil {
+		return books.Book{}, err
+	}
+	return v.(books.Book), nil
+}
+
+// Stats returns the current coalescing counters.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *Client) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if ok {
+		c.stats.Shared++
+	} else {
+		upstreamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = cl
+		c.stats.Upstream++
+		go c.run(upstreamCtx, key, cl, fn)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		c.leave(key, cl)
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) run(ctx context.Context, key string, cl *call, fn func(context.Context) (any, error)) {
+	defer cl.cancel()
+	cl.value, cl.err = fn(ctx)
+
+	c.mu.Lock()
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+func (c *Client) leave(key string, cl *call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl.waiters--
+	if cl.waiters > 0 {
+		return
+	}
+	// Nobody is waiting anymore: abandon the upstream call and let the next
+	// caller start a fresh one.
+	cl.cancel()
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+}
+
+var _ books.ExternalClient = (*Client)(nil)
@@ -0,0 +1,155 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// blockingClient holds every search until release is closed or its context ends.
+type blockingClient struct {
+	calls    atomic.Int32
+	release  chan struct{}
+	canceled chan struct{}
+}
+
+func newBlockingClient() *blockingClient {
+	return &blockingClient{release: make(chan struct{}), canceled: make(chan struct{}, 1)}
+}
+
+func (c *blockingClient) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	c.calls.Add(1)
+	select {
+	case <-c.release:
+		return books.SearchResult{TotalItems: 1, Items: []books.Book{{ID: p.Query}}}, nil
+	case <-ctx.Done():
+		c.canceled <- struct{}{}
+		return books.SearchResult{}, ctx.Err()
+	}
+}
+
+func (c *blockingClient) Get(ctx context.Context, id string) (books.Book, error) {
+	return books.Book{}, books.ErrNotFound
+}
+
+// waitForCallers waits until n callers have joined calls on c.
+func waitForCallers(t *testing.T, c *Client, n uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		st := c.Stats()
+		if st.Upstream+st.Shared >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d callers joined", st.Upstream+st.Shared, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrentIdenticalSearchesShareOneCall(t *testing.T) {
+	up := newBlockingClient()
+	c := New(up)
+	const callers = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := c.Search(context.Background(), books.SearchParams{Query: "golang"})
+			if err == nil && (len(res.Items) != 1 || res.Items[0].ID != "golang") {
+				err = errors.New("unexpected result")
+			}
+			errs <- err
+		}()
+	}
+	waitForCallers(t, c, callers)
+	close(up.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := up.calls.Load(); n != 1 {
+		t.Fatalf("upstream calls = %d, want 1", n)
+	}
+	if st := c.Stats(); st.Upstream != 1 || st.Shared != callers-1 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestCancelingOneCallerLeavesOthers(t *testing.T) {
+	up := newBlockingClient()
+	c := New(up)
+	params := books.SearchParams{Query: "golang"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := c.Search(ctx, params)
+		canceled <- err
+	}()
+	waitForCallers(t, c, 1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Search(context.Background(), params)
+		done <- err
+	}()
+	waitForCallers(t, c, 2)
+
+	cancel()
+	if err := <-canceled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled caller: err = %v", err)
+	}
+	select {
+	case <-up.canceled:
+		t.Fatal("upstream call was canceled while another caller was waiting")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(up.release)
+	if err := <-done; err != nil {
+		t.Fatalf("remaining caller: err = %v", err)
+	}
+	if n := up.calls.Load(); n != 1 {
+		t.Fatalf("upstream calls = %d, want 1", n)
+	}
+}
+
+func TestUpstreamIsCanceledWhenEveryCallerLeaves(t *testing.T) {
+	up := newBlockingClient()
+	c := New(up)
+
+	// The upstream context drops the caller's deadline; leaving still cancels it.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Search(ctx, books.SearchParams{Query: "golang"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case <-up.canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("upstream call kept running after its only caller left")
+	}
+
+	// The abandoned call is forgotten, so the next caller starts a fresh one.
+	close(up.release)
+	if _, err := c.Search(context.Background(), books.SearchParams{Query: "golang"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := up.calls.Load(); n != 2 {
+		t.Fatalf("upstream calls = %d, want 2", n)
+	}
+}
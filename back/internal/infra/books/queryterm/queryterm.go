@@ -0,0 +1,39 @@
+// Package queryterm escapes user supplied values for the search query syntax shared by
+// Google Books and Open Library (quoted phrases, a leading - for exclusion, OR / AND,
+// field prefixes such as intitle:, and grouping with parentheses).
+package queryterm
+
+import "strings"
+
+// Sanitize drops quotes and collapses whitespace. Neither provider has a way to escape
+// a quote inside a quoted phrase, so quotes in the value are removed.
+func Sanitize(v string) string {
+	v = strings.NewReplacer(`"`, " ", "“", " ", "”", " ").Replace(v)
+	return strings.Join(strings.Fields(v), " ")
+}
+
+// Quote returns v as a single term, quoted when it would otherwise be read as query syntax.
+// It returns "" when nothing is left after sanitizing.
+func Quote(v string) string {
+	v = Sanitize(v)
+	if v == "" {
+		return ""
+	}
+	if needsQuote(v) {
+		return `"` + v + `"`
+	}
+	return v
+}
+
+// needsQuote reports whether v contains whitespace or operator characters (including |),
+// starts with the exclusion prefix, or is one of the boolean operators.
+func needsQuote(v string) bool {
+	if strings.ContainsAny(v, " ():+|") || strings.HasPrefix(v, "-") {
+		return true
+	}
+	switch v {
+	case "OR", "AND":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package queryterm
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"golang", "golang"},
+		{"go-kit", "go-kit"},
+		{"O'Reilly", "O'Reilly"},
+		{"or", "or"},
+		{"go  lang", `"go lang"`},
+		{"-go", `"-go"`},
+		{"OR", `"OR"`},
+		{"AND", `"AND"`},
+		{"|", `"|"`},
+		{"a|b", `"a|b"`},
+		{"C++", `"C++"`},
+		{"(go)", `"(go)"`},
+		{"isbn:123", `"isbn:123"`},
+		{`say "hi"`, `"say hi"`},
+		{"“go”", "go"},
+		{`" "`, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.value); got != tt.want {
+			t.Errorf("Quote(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"  clean   code ", "clean code"},
+		{`clean "code"`, "clean code"},
+		{"“clean”code", "clean code"},
+		{`""`, ""},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.value); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
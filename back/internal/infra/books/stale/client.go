@@ -0,0 +1,228 @@
+package stale
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// Options configures the stale-if-error store.
+type Options struct {
+	// Dir is the directory the successful search responses are written to.
+	Dir string
+	// MaxAge is the oldest response that may still be served when the upstream fails.
+	MaxAge time.Duration
+	// MaxEntries caps the number of saved responses; Prune removes the oldest beyond it.
+	MaxEntries int
+}
+
+// DefaultMaxEntries is used when Options.MaxEntries is not positive.
+const DefaultMaxEntries = 10000
+
+// Client persists successful search responses of another books.ExternalClient on disk
+// and serves them, marked as stale, when the wrapped client fails.
+type Client struct {
+	next       books.ExternalClient
+	dir        string
+	maxAge     time.Duration
+	maxEntries int
+	now        func() time.Time
+}
+
+type record struct {
+	Key     string             `json:"key"`
+	SavedAt time.Time          `json:"savedAt"`
+	Result  books.SearchResult `json:"result"`
+}
+
+// New wraps next with an on-disk stale-if-error store rooted at opts.Dir.
+func New(next books.ExternalClient, opts Options) (*Client, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("stale store directory is required")
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 24 * time.Hour
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Client{next: next, dir: opts.Dir, maxAge: opts.MaxAge, maxEntries: opts.MaxEntries, now: time.Now}, nil
+}
+
+// WithNow overrides the now function (primarily for testing).
+func (c *Client) WithNow(fn func() time.Time) {
+	if fn != nil {
+		c.now = fn
+	}
+}
+
+// Search delegates to the wrapped client, saving complete responses and falling back
+// to the saved response when the upstream fails.
+func (c *Client) Search(ctx context.Context, params books.SearchParams) (books.SearchResult, error) {
+	key := params.Key()
+	res, err := c.next.Search(ctx, params)
+	if err == nil {
+		if !res.Stale && len(res.ProviderErrors) == 0 {
+			if err := c.save(key, res); err != nil {
+				log.Printf("stale store: failed to save search response: %v", err)
+			}
+		}
+		return res, nil
+	}
+	if !fallback(err) {
+		return books.SearchResult{}, err
+	}
+
+	rec, ok := c.load(key)
+	if !ok {
+		return books.SearchResult{}, err
+	}
+	age := c.now().Sub(rec.SavedAt)
+	log.Printf("stale store: serving a %s old search response: %v", age.Round(time.Second), err)
+	stale := rec.Result
+	stale.Stale = true
+	stale.StaleAgeSeconds = max(stale.StaleAgeSeconds, int(age.Seconds()))
+	return stale, nil
+}
+
+// Get is passed through; only search responses are kept.
+func (c *Client) Get(ctx context.Context, id string) (books.Book, error) {
+	return c.next.Get(ctx, id)
+}
+
+// fallback reports whether err is an upstream failure a stale response may stand in for.
+// Cancellations and answers such as "not found" are returned as they are.
+func fallback(err error) bool {
+	if errors.Is(err, books.ErrCanceled) || errors.Is(err, context.Canceled) || errors.Is(err, books.ErrNotFound) {
+		return false
+	}
+	for _, kind := range []error{
+		books.ErrRateLimited,
+		books.ErrUpstreamTimeout,
+		books.ErrUpstreamUnauthorized,
+		books.ErrBadUpstreamPayload,
+		books.ErrUpstreamUnavailable,
+		books.ErrUpstream,
+	} {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Client) load(key string) (record, bool) {
+	path := c.path(key)
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("stale store: failed to read %s: %v", path, err)
+		}
+		return record{}, false
+	}
+	var rec record
+	if err := json.Unmarshal(bytes, &rec); err != nil || rec.Key != key {
+		return record{}, false
+	}
+	if c.now().Sub(rec.SavedAt) > c.maxAge {
+		_ = os.Remove(path)
+		return record{}, false
+	}
+	return rec, true
+}
+
+func (c *Client) save(key string, res books.SearchResult) error {
+	tmp, err := os.CreateTemp(c.dir, "search-*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(tmp).Encode(record{Key: key, SavedAt: c.now(), Result: res}); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Prune removes saved responses older than the maximum age, then the oldest ones beyond
+// the maximum number of entries, and returns how many were removed.
+func (c *Client) Prune() (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+	type saved struct {
+		name    string
+		modTime time.Time
+	}
+	var kept []saved
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if c.now().Sub(info.ModTime()) <= c.maxAge {
+			kept = append(kept, saved{name: e.Name(), modTime: info.ModTime()})
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err == nil {
+			removed++
+		}
+	}
+	if excess := len(kept) - c.maxEntries; excess > 0 {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].modTime.Before(kept[j].modTime) })
+		for _, e := range kept[:excess] {
+			if err := os.Remove(filepath.Join(c.dir, e.name)); err == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// RunPruner prunes the store every tick until ctx is canceled, so it stays bounded
+// while the server runs.
+func (c *Client) RunPruner(ctx context.Context, tick time.Duration) {
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := c.Prune(); err != nil {
+				log.Printf("stale store: failed to prune: %v", err)
+			} else if n > 0 {
+				log.Printf("stale store: pruned %d saved search responses", n)
+			}
+		}
+	}
+}
+
+var _ books.ExternalClient = (*Client)(nil)
@@ -0,0 +1,135 @@
+package stale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// upstream answers searches until err is set.
+type upstream struct {
+	err error
+}
+
+func (u *upstream) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	if u.err != nil {
+		return books.SearchResult{}, u.err
+	}
+	return books.SearchResult{TotalItems: 1, Items: []books.Book{{ID: p.Query}}}, nil
+}
+
+func (u *upstream) Get(ctx context.Context, id string) (books.Book, error) {
+	return books.Book{}, books.ErrNotFound
+}
+
+func TestSearchFallsBackToSavedResponse(t *testing.T) {
+	up := &upstream{}
+	c, err := New(up, Options{Dir: t.TempDir(), MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.WithNow(func() time.Time { return now })
+
+	params := books.SearchParams{Query: "golang"}
+	if _, err := c.Search(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+
+	up.err = &books.UpstreamError{Kind: books.ErrUpstreamUnavailable, StatusCode: 503}
+	now = now.Add(10 * time.Minute)
+	res, err := c.Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("expected the saved response, got %v", err)
+	}
+	if !res.Stale || res.StaleAgeSeconds != 600 || len(res.Items) != 1 {
+		t.Fatalf("unexpected stale response: %+v", res)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := c.Search(context.Background(), params); !errors.Is(err, books.ErrUpstreamUnavailable) {
+		t.Fatalf("expired response was served; err = %v", err)
+	}
+}
+
+func TestSearchDoesNotHideCancellation(t *testing.T) {
+	up := &upstream{}
+	c, err := New(up, Options{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := books.SearchParams{Query: "golang"}
+	if _, err := c.Search(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	up.err = books.ErrCanceled
+	if _, err := c.Search(context.Background(), params); !errors.Is(err, books.ErrCanceled) {
+		t.Fatalf("err = %v, want ErrCanceled", err)
+	}
+}
+
+func TestPruneEnforcesMaxAgeAndMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(&upstream{}, Options{Dir: dir, MaxAge: time.Hour, MaxEntries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := c.Search(context.Background(), books.SearchParams{Query: fmt.Sprintf("q%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+		// q0 is expired, q1..q5 are fresh with q1 the oldest
+		age := time.Duration(6-i) * time.Minute
+		if i == 0 {
+			age = 2 * time.Hour
+		}
+		path := c.path(books.SearchParams{Query: fmt.Sprintf("q%d", i)}.Key())
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := c.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 3 {
+		t.Fatalf("removed = %d, want 3", removed)
+	}
+	left, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(left) != 3 {
+		t.Fatalf("%d responses left, want 3", len(left))
+	}
+	for i := 3; i < 6; i++ {
+		if _, err := os.Stat(c.path(books.SearchParams{Query: fmt.Sprintf("q%d", i)}.Key())); err != nil {
+			t.Fatalf("newest response q%d was removed", i)
+		}
+	}
+}
+
+func TestFallbackKinds(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&books.UpstreamError{Kind: books.ErrUpstreamUnavailable}, true},
+		{&books.UpstreamError{Kind: books.ErrRateLimited}, true},
+		{&books.UpstreamError{Kind: books.ErrUpstreamTimeout}, true},
+		{&books.UpstreamError{Kind: books.ErrCanceled}, false},
+		{books.ErrNotFound, false},
+		// a replay miss must stay visible instead of being answered from an older recording
+		{&books.UpstreamError{Kind: books.ErrUpstreamNotRecorded}, false},
+	}
+	for _, tt := range tests {
+		if got := fallback(tt.err); got != tt.want {
+			t.Errorf("fallback(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package cassette records upstream HTTP exchanges to a directory and replays them,
+// so a search can be reproduced deterministically from a bug report.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NotRecordedError is returned in strict replay mode for requests without a cassette.
+// Upstream clients match it with errors.As to tell a replay miss from a network failure.
+type NotRecordedError struct {
+	Method string
+	URL    string
+}
+
+func (e *NotRecordedError) Error() string {
+	return fmt.Sprintf("cassette: request was not recorded: %s %s", e.Method, e.URL)
+}
+
+// Mode selects how the transport uses the cassette directory.
+type Mode string
+
+const (
+	// ModeRecord sends every request upstream and writes the exchange to the directory.
+	ModeRecord Mode = "record"
+	// ModeReplay serves only recorded exchanges and fails any other request.
+	ModeReplay Mode = "replay"
+	// ModeReplayFallthrough serves recorded exchanges and records the requests it has not seen.
+	ModeReplayFallthrough Mode = "replay-fallthrough"
+)
+
+// ParseMode validates a mode name.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ModeRecord, ModeReplay, ModeReplayFallthrough:
+		return m, nil
+	}
+	return "", fmt.Errorf("cassette: unknown mode %q (want record, replay or replay-fallthrough)", s)
+}
+
+// secretParams are dropped from recorded URLs and from the cassette key.
+var secretParams = []string{"key"}
+
+// Transport is an http.RoundTripper that records to or replays from a cassette directory.
+type Transport struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+	now  func() time.Time
+}
+
+// Exchange is the on-disk form of one recorded request/response pair.
+type Exchange struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recordedAt"`
+}
+
+// RecordedRequest identifies the request an exchange answers.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is the upstream response as it was received.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// New creates a transport for dir in the given mode. next is used to reach the upstream
+// (http.DefaultTransport when nil).
+func New(dir string, mode Mode, next http.RoundTripper) (*Transport, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("cassette: directory is required")
+	}
+	if _, err := ParseMode(string(mode)); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{dir: dir, mode: mode, next: next, now: time.Now}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := Key(req)
+	if t.mode != ModeRecord {
+		ex, err := t.load(req.Method, key)
+		switch {
+		case err == nil:
+			return ex.Response.toResponse(req), nil
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		case t.mode == ModeReplay:
+			return nil, &NotRecordedError{Method: req.Method, URL: key}
+		}
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	ex := Exchange{
+		Request:    RecordedRequest{Method: req.Method, URL: key},
+		Response:   RecordedResponse{StatusCode: res.StatusCode, Header: recordedHeader(res.Header), Body: string(body)},
+		RecordedAt: t.now().UTC(),
+	}
+	if err := t.save(key, ex); err != nil {
+		return nil, fmt.Errorf("cassette: save: %w", err)
+	}
+	return res, nil
+}
+
+// Key is the request URL with the secret parameters removed and the query sorted.
+func Key(req *http.Request) string {
+	u := *req.URL
+	q := u.Query()
+	for _, p := range secretParams {
+		q.Del(p)
+	}
+	u.RawQuery = q.Encode()
+	u.User = nil
+	return u.String()
+}
+
+// Path returns the cassette file for a request key, relative to the directory.
+func Path(method, key string) string {
+	sum := sha256.Sum256([]byte(method + " " + key))
+	return hex.EncodeToString(sum[:8]) + ".json"
+}
+
+func (t *Transport) load(method, key string) (Exchange, error) {
+	b, err := os.ReadFile(filepath.Join(t.dir, Path(method, key)))
+	if err != nil {
+		return Exchange{}, err
+	}
+	var ex Exchange
+	if err := json.Unmarshal(b, &ex); err != nil {
+		return Exchange{}, fmt.Errorf("cassette: decode %s: %w", key, err)
+	}
+	return ex, nil
+}
+
+func (t *Transport) save(key string, ex Exchange) error {
+	tmp, err := os.CreateTemp(t.dir, "cassette-*.tmp")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(ex); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(t.dir, Path(ex.Request.Method, key)))
+}
+
+// recordedHeader keeps the headers the client looks at.
+func recordedHeader(h http.Header) http.Header {
+	out := http.Header{}
+	for _, k := range []string{"Content-Type", "Retry-After"} {
+		if v := h.Values(k); len(v) > 0 {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (r RecordedResponse) toResponse(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
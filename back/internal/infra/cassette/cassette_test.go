@@ -0,0 +1,136 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// upstream answers every request with its path and counts the requests it receives.
+func upstream(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "3")
+		w.Header().Set("X-Request-Id", "dropped")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"path":"`+r.URL.Path+`"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string) (*http.Response, string, error) {
+	t.Helper()
+	res, err := (&http.Client{Transport: rt}).Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(body), nil
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	srv, calls := upstream(t)
+	dir := t.TempDir()
+
+	record, err := New(dir, ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body, err := get(t, record, srv.URL+"/volumes?q=go&key=secret"); err != nil || body != `{"path":"/volumes"}` {
+		t.Fatalf("record = %q, %v", body, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("cassettes = %v, want 1 file", files)
+	}
+	raw, _ := os.ReadFile(files[0])
+	if strings.Contains(string(raw), "secret") || strings.Contains(string(raw), "X-Request-Id") {
+		t.Errorf("cassette keeps the API key or unused headers:\n%s", raw)
+	}
+
+	replay, err := New(dir, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the API key is not part of the key, so a different key replays the same exchange
+	res, body, err := get(t, replay, srv.URL+"/volumes?key=other&q=go")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") != "3" || body != `{"path":"/volumes"}` {
+		t.Errorf("replay = %d %v %q, want the recorded response", res.StatusCode, res.Header, body)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream calls = %d, want 1 (replay must not reach upstream)", n)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	srv, calls := upstream(t)
+	replay, err := New(t.TempDir(), ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = get(t, replay, srv.URL+"/volumes?q=go&key=secret")
+
+	var nr *NotRecordedError
+	if !errors.As(err, &nr) {
+		t.Fatalf("err = %v, want *NotRecordedError", err)
+	}
+	if nr.Method != http.MethodGet || strings.Contains(nr.URL, "secret") || !strings.Contains(nr.URL, "q=go") {
+		t.Errorf("NotRecordedError = %+v", nr)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("upstream calls = %d, want 0", n)
+	}
+}
+
+func TestReplayFallthroughRecordsMisses(t *testing.T) {
+	srv, calls := upstream(t)
+	tr, err := New(t.TempDir(), ModeReplayFallthrough, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, body, err := get(t, tr, srv.URL+"/volumes?q=go"); err != nil || body != `{"path":"/volumes"}` {
+			t.Fatalf("request %d = %q, %v", i, body, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("upstream calls = %d, want 1 (the second request is replayed)", n)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Mode
+		wantErr bool
+	}{
+		{"record", ModeRecord, false},
+		{" Replay ", ModeReplay, false},
+		{"replay-fallthrough", ModeReplayFallthrough, false},
+		{"playback", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package googlebooks
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// サーキットブレーカーが開いている間に返すエラー
+var ErrCircuitOpen = errors.New("googlebooks circuit breaker is open")
+
+// サーキットブレーカーの状態
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// サーキットブレーカーの設定
+type BreakerSettings struct {
+	// 連続失敗がこの回数に達したら open にする
+	FailureThreshold int
+	// open から half-open へ移るまでの待ち時間
+	Cooldown time.Duration
+	// 状態遷移時に呼ばれるフック（任意）
+	OnStateChange func(from, to BreakerState)
+}
+
+// 上流が明らかに落ちているときに即時失敗させるサーキットブレーカー
+type Breaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	lastError error
+}
+
+func NewBreaker(settings BreakerSettings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.Cooldown <= 0 {
+		settings.Cooldown = 30 * time.Second
+	}
+	return &Breaker{
+		settings: settings,
+		now:      time.Now,
+		state:    BreakerClosed,
+	}
+}
+
+// 現在の状態を返す（cooldown 経過後は half-open として報告する）
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.cooledDown() {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// 直近で失敗としてカウントしたエラー
+func (b *Breaker) LastError() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastError
+}
+
+// リクエストを通してよいか判定する。half-open では同時に1件だけ試行を許す
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if !b.cooledDown() {
+			return ErrCircuitOpen
+		}
+		b.transition(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// 試行結果を記録する。failure=false なら成功扱い
+func (b *Breaker) record(failure bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failure {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.transition(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	b.lastError = err
+	if b.state == BreakerHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.openedAt = b.now()
+		if b.state != BreakerOpen {
+			b.transition(BreakerOpen)
+		}
+	}
+}
+
+// 試行を結果なしで打ち切った場合（呼び出し元のキャンセルなど）に half-open の枠を戻す
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// open 状態が解除されるまでの残り時間
+func (b *Breaker) retryAfter() time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != BreakerOpen {
+		return 0
+	}
+	if d := b.openedAt.Add(b.settings.Cooldown).Sub(b.now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func (b *Breaker) cooledDown() bool {
+	return !b.now().Before(b.openedAt.Add(b.settings.Cooldown))
+}
+
+func (b *Breaker) transition(to BreakerState) {
+	from := b.state
+	b.state = to
+	log.Printf("googlebooks: circuit breaker %s -> %s (consecutive failures: %d)", from, to, b.failures)
+	if b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
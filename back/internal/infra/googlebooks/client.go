@@ -3,6 +3,7 @@ package googlebooks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/cassette"
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
 )
 
@@ -18,6 +20,8 @@ type Client struct {
 	baseURL string
 	apiKey  string
 	http    *http.Client
+	retry   RetryPolicy
+	breaker *Breaker
 }
 
 func NewClient(baseURL, apiKey string) *Client {
@@ -28,9 +32,37 @@ func NewClient(baseURL, apiKey string) *Client {
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		http:    &http.Client{Timeout: 5 * time.Second},
+		retry:   DefaultRetryPolicy(),
+		breaker: NewBreaker(BreakerSettings{}),
 	}
 }
 
+// リトライ設定を差し替える
+func (c *Client) WithRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 1
+	}
+	c.retry = p
+}
+
+// HTTP のトランスポートを差し替える（録画・再生用。nil で既定に戻す）
+func (c *Client) WithTransport(rt http.RoundTripper) {
+	c.http.Transport = rt
+}
+
+// サーキットブレーカーを差し替える（nil で無効化）
+func (c *Client) WithBreaker(b *Breaker) {
+	c.breaker = b
+}
+
+// サーキットブレーカーの現在の状態（無効な場合は closed）
+func (c *Client) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	return c.breaker.State()
+}
+
 // Google Books を検索する
 func (c *Client) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
 	q := buildQuery(p)
@@ -45,14 +77,21 @@ func (c *Client) Search(ctx context.Context, p books.SearchParams) (books.Search
 	} else {
 		params.Set("orderBy", "relevance")
 	}
+	if p.EbookFilter != "" {
+		params.Set("filter", p.EbookFilter)
+	}
+	if p.Download != "" {
+		params.Set("download", p.Download)
+	}
 	// 明示的に startIndex を常に送る（0 の場合も）
 	params.Set("startIndex", fmt.Sprintf("%d", p.StartIndex))
 	max := p.MaxResults
 	if max <= 0 {
 		max = 10
 	}
-	if max > 10 {
-		max = 10
+	// Google Books API の上限は 40 件
+	if max > 40 {
+		max = 40
 	}
 	params.Set("maxResults", fmt.Sprintf("%d", max))
 	if c.apiKey != "" {
@@ -61,48 +100,189 @@ func (c *Client) Search(ctx context.Context, p books.SearchParams) (books.Search
 	// fields を指定すると maxResults が正しく反映されない場合があるため未指定とする
 
 	endpoint := c.baseURL + "?" + params.Encode()
+	var gr googleResponse
+	if err := c.getJSON(ctx, endpoint, &gr); err != nil {
+		return books.SearchResult{}, err
+	}
+
+	mapped := books.SearchResult{TotalItems: gr.TotalItems}
+	for _, it := range gr.Items {
+		mapped.Items = append(mapped.Items, toBook(it))
+	}
+	return mapped, nil
+}
+
+// ボリューム ID で1冊を取得する（volumes/{id}）
+func (c *Client) Get(ctx context.Context, id string) (books.Book, error) {
+	endpoint := c.baseURL + "/" + url.PathEscape(id)
+	if c.apiKey != "" {
+		endpoint += "?" + url.Values{"key": {c.apiKey}}.Encode()
+	}
+
+	var it googleItem
+	if err := c.getJSON(ctx, endpoint, &it); err != nil {
+		return books.Book{}, err
+	}
+	if it.ID == "" {
+		return books.Book{}, books.ErrNotFound
+	}
+	return toBook(it), nil
+}
+
+// Google Books のボリュームを books.Book に変換する
+func toBook(it googleItem) books.Book {
+	b := books.Book{
+		ID:            it.ID,
+		Title:         it.VolumeInfo.Title,
+		Authors:       it.VolumeInfo.Authors,
+		PublishedDate: it.VolumeInfo.PublishedDate,
+		Description:   it.VolumeInfo.Description,
+		Categories:    it.VolumeInfo.Categories,
+		PageCount:     it.VolumeInfo.PageCount,
+		Thumbnail:     it.VolumeInfo.ImageLinks.Thumbnail,
+		InfoLink:      it.VolumeInfo.InfoLink,
+		Subtitle:      it.VolumeInfo.Subtitle,
+		Publisher:     it.VolumeInfo.Publisher,
+		Language:      it.VolumeInfo.Language,
+		AverageRating: it.VolumeInfo.AverageRating,
+		RatingsCount:  it.VolumeInfo.RatingsCount,
+		PreviewLink:   it.VolumeInfo.PreviewLink,
+		TextSnippet:   it.SearchInfo.TextSnippet,
+	}
+	b.ISBN10, b.ISBN13 = isbns(it.VolumeInfo.IndustryIdentifiers)
+	if d, err := books.ParseDate(it.VolumeInfo.PublishedDate); err == nil {
+		b.Published = &d
+	}
+	if img := it.VolumeInfo.ImageLinks; img != (googleImageLinks{}) {
+		b.Images = &books.ImageLinks{
+			SmallThumbnail: img.SmallThumbnail,
+			Thumbnail:      img.Thumbnail,
+			Small:          img.Small,
+			Medium:         img.Medium,
+			Large:          img.Large,
+			ExtraLarge:     img.ExtraLarge,
+		}
+	}
+	if s := it.SaleInfo; s != nil {
+		b.Sale = &books.SaleInfo{
+			Country:     s.Country,
+			Saleability: s.Saleability,
+			IsEbook:     s.IsEbook,
+			ListPrice:   s.ListPrice.toPrice(),
+			RetailPrice: s.RetailPrice.toPrice(),
+			BuyLink:     s.BuyLink,
+		}
+	}
+	if a := it.AccessInfo; a != nil {
+		b.Access = &books.AccessInfo{
+			Viewability:      a.Viewability,
+			PublicDomain:     a.PublicDomain,
+			EpubAvailable:    a.Epub.IsAvailable,
+			PdfAvailable:     a.Pdf.IsAvailable,
+			EpubDownloadLink: a.Epub.DownloadLink,
+			PdfDownloadLink:  a.Pdf.DownloadLink,
+			WebReaderLink:    a.WebReader,
+		}
+	}
+	return b
+}
+
+// 価格が無い（無料・販売なし）場合は nil
+func (p *googlePrice) toPrice() *books.Price {
+	if p == nil || p.CurrencyCode == "" {
+		return nil
+	}
+	return &books.Price{Amount: p.Amount, CurrencyCode: p.CurrencyCode}
+}
+
+// industryIdentifiers から検証済みの ISBN-10 / ISBN-13 を取り出す
+func isbns(ids []googleIdentifier) (isbn10, isbn13 string) {
+	for _, id := range ids {
+		v := strings.ReplaceAll(strings.ToUpper(id.Identifier), "-", "")
+		switch id.Type {
+		case "ISBN_10":
+			if books.ValidISBN10(v) {
+				isbn10 = v
+			}
+		case "ISBN_13":
+			if books.ValidISBN13(v) {
+				isbn13 = v
+			}
+		}
+	}
+	if isbn13 == "" && isbn10 != "" {
+		isbn13, _ = books.ISBN10To13(isbn10)
+	}
+	return isbn10, isbn13
+}
+
+// サーキットブレーカーとリトライを通して GET し、JSON をデコードする
+func (c *Client) getJSON(ctx context.Context, endpoint string, out any) error {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return c.classify(ctx, err)
+		}
+	}
+
+	var err error
+	for attempt := 0; attempt < c.retry.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			wait, ok := c.retry.backoff(attempt-1, err)
+			if !ok {
+				break
+			}
+			if serr := sleep(ctx, wait); serr != nil {
+				err = serr
+				break
+			}
+		}
+		err = c.getOnce(ctx, endpoint, out)
+		if err == nil || !retryable(ctx, err) {
+			break
+		}
+	}
+
+	if c.breaker != nil {
+		if ctx.Err() != nil && err != nil {
+			c.breaker.release()
+		} else {
+			c.breaker.record(breakerFailure(ctx, err), err)
+		}
+	}
+	return c.classify(ctx, err)
+}
+
+// 1回分の GET を行う
+func (c *Client) getOnce(ctx context.Context, endpoint string, out any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return books.SearchResult{}, err
+		return err
 	}
 
 	res, err := c.http.Do(req)
 	if err != nil {
-		return books.SearchResult{}, err
+		var nr *cassette.NotRecordedError
+		if errors.As(err, &nr) {
+			// 再生専用モードの録画漏れは何度送っても同じなので、通信エラーとして扱わない
+			return err
+		}
+		return &upstreamIOError{err: err}
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
-		return books.SearchResult{}, fmt.Errorf("googlebooks upstream status %d: %s", res.StatusCode, strings.TrimSpace(string(b)))
-	}
-
-	var gr googleResponse
-	if err := json.NewDecoder(res.Body).Decode(&gr); err != nil {
-		return books.SearchResult{}, err
-	}
-
-	mapped := books.SearchResult{TotalItems: gr.TotalItems}
-	for _, it := range gr.Items {
-		b := books.Book{
-			ID:            it.ID,
-			Title:         it.VolumeInfo.Title,
-			Authors:       it.VolumeInfo.Authors,
-			PublishedDate: it.VolumeInfo.PublishedDate,
-			Description:   it.VolumeInfo.Description,
-			Categories:    it.VolumeInfo.Categories,
-			PageCount:     it.VolumeInfo.PageCount,
-			Thumbnail:     it.VolumeInfo.ImageLinks.Thumbnail,
-			InfoLink:      it.VolumeInfo.InfoLink,
+		return &statusError{
+			StatusCode: res.StatusCode,
+			Body:       strings.TrimSpace(string(b)),
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
 		}
-		mapped.Items = append(mapped.Items, b)
 	}
-	return mapped, nil
-}
 
-func buildQuery(p books.SearchParams) string {
-	// カテゴリは廃止し、q そのものをGoogle Booksへ渡す
-	return strings.TrimSpace(p.Query)
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return &payloadError{err: err}
+	}
+	return nil
 }
 
 // Google Books API レスポンスの構造体
@@ -114,19 +294,70 @@ type googleResponse struct {
 type googleItem struct {
 	ID         string           `json:"id"`
 	VolumeInfo googleVolumeInfo `json:"volumeInfo"`
+	SearchInfo struct {
+		TextSnippet string `json:"textSnippet"`
+	} `json:"searchInfo"`
+	SaleInfo   *googleSaleInfo   `json:"saleInfo"`
+	AccessInfo *googleAccessInfo `json:"accessInfo"`
+}
+
+type googleSaleInfo struct {
+	Country     string       `json:"country"`
+	Saleability string       `json:"saleability"`
+	IsEbook     bool         `json:"isEbook"`
+	ListPrice   *googlePrice `json:"listPrice"`
+	RetailPrice *googlePrice `json:"retailPrice"`
+	BuyLink     string       `json:"buyLink"`
+}
+
+type googlePrice struct {
+	Amount       float64 `json:"amount"`
+	CurrencyCode string  `json:"currencyCode"`
+}
+
+type googleAccessInfo struct {
+	Viewability  string       `json:"viewability"`
+	PublicDomain bool         `json:"publicDomain"`
+	Epub         googleFormat `json:"epub"`
+	Pdf          googleFormat `json:"pdf"`
+	WebReader    string       `json:"webReaderLink"`
+}
+
+type googleFormat struct {
+	IsAvailable  bool   `json:"isAvailable"`
+	DownloadLink string `json:"downloadLink"`
 }
 
 type googleVolumeInfo struct {
-	Title         string   `json:"title"`
-	Authors       []string `json:"authors"`
-	PublishedDate string   `json:"publishedDate"`
-	Description   string   `json:"description"`
-	Categories    []string `json:"categories"`
-	PageCount     int      `json:"pageCount"`
-	ImageLinks    struct {
-		Thumbnail string `json:"thumbnail"`
-	} `json:"imageLinks"`
-	InfoLink string `json:"infoLink"`
+	Title               string             `json:"title"`
+	Authors             []string           `json:"authors"`
+	PublishedDate       string             `json:"publishedDate"`
+	Description         string             `json:"description"`
+	Categories          []string           `json:"categories"`
+	PageCount           int                `json:"pageCount"`
+	ImageLinks          googleImageLinks   `json:"imageLinks"`
+	InfoLink            string             `json:"infoLink"`
+	IndustryIdentifiers []googleIdentifier `json:"industryIdentifiers"`
+	Subtitle            string             `json:"subtitle"`
+	Publisher           string             `json:"publisher"`
+	Language            string             `json:"language"`
+	AverageRating       float64            `json:"averageRating"`
+	RatingsCount        int                `json:"ratingsCount"`
+	PreviewLink         string             `json:"previewLink"`
+}
+
+type googleImageLinks struct {
+	SmallThumbnail string `json:"smallThumbnail"`
+	Thumbnail      string `json:"thumbnail"`
+	Small          string `json:"small"`
+	Medium         string `json:"medium"`
+	Large          string `json:"large"`
+	ExtraLarge     string `json:"extraLarge"`
+}
+
+type googleIdentifier struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
 }
 
 var _ books.ExternalClient = (*Client)(nil)
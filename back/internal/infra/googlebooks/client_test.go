@@ -0,0 +1,170 @@
+package googlebooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/cassette"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/googlebooks/fakeserver"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// fakeClient returns a client for a fresh fake server with fast retries.
+func fakeClient(t *testing.T, opts fakeserver.Options) (*Client, *fakeserver.TestServer) {
+	t.Helper()
+	ts := fakeserver.NewTestServer(t, opts)
+	c := NewClient(ts.BaseURL(), "")
+	c.WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+	return c, ts
+}
+
+func TestClientSearch(t *testing.T) {
+	c, _ := fakeClient(t, fakeserver.Options{})
+
+	res, err := c.Search(context.Background(), books.SearchParams{Title: "The Go Programming Language", MaxResults: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TotalItems == 0 || len(res.Items) == 0 {
+		t.Fatalf("no results: %+v", res)
+	}
+	for _, b := range res.Items {
+		if b.ID == "" || b.Title == "" {
+			t.Errorf("book without ID or title: %+v", b)
+		}
+	}
+
+	page, err := c.Search(context.Background(), books.SearchParams{Query: "programming", StartIndex: 0, MaxResults: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("maxResults=2 returned %d items", len(page.Items))
+	}
+}
+
+func TestClientGet(t *testing.T) {
+	c, _ := fakeClient(t, fakeserver.Options{})
+
+	b, err := c.Get(context.Background(), "gbGoLang01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != "gbGoLang01" || b.ISBN13 == "" {
+		t.Fatalf("unexpected book: %+v", b)
+	}
+
+	_, err = c.Get(context.Background(), "no-such-volume")
+	if !errors.Is(err, books.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientRateLimitedWithRetryAfter(t *testing.T) {
+	// Retry-After が MaxDelay を超えるのでリトライせずにそのまま返す
+	c, ts := fakeClient(t, fakeserver.Options{Fault: fakeserver.Fault{RetryAfter: 7 * time.Second}})
+	ts.FailNext(1, http.StatusTooManyRequests)
+
+	_, err := c.Search(context.Background(), books.SearchParams{Query: "go"})
+	if !errors.Is(err, books.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	var ue *books.UpstreamError
+	if !errors.As(err, &ue) || ue.StatusCode != http.StatusTooManyRequests || ue.RetryAfter != 7*time.Second {
+		t.Fatalf("unexpected upstream error: %#v", err)
+	}
+	if n := ts.Requests(); n != 1 {
+		t.Fatalf("requests = %d, want 1", n)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	c, ts := fakeClient(t, fakeserver.Options{})
+	ts.FailNext(2, http.StatusServiceUnavailable)
+
+	res, err := c.Search(context.Background(), books.SearchParams{Query: "go"})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if len(res.Items) == 0 {
+		t.Fatal("no results after retries")
+	}
+	if n := ts.Requests(); n != 3 {
+		t.Fatalf("requests = %d, want 3", n)
+	}
+	if st := c.BreakerState(); st != BreakerClosed {
+		t.Fatalf("breaker = %s, want closed", st)
+	}
+}
+
+func TestClientBreakerOpensAfterRetriedFailures(t *testing.T) {
+	c, ts := fakeClient(t, fakeserver.Options{})
+	c.WithBreaker(NewBreaker(BreakerSettings{FailureThreshold: 2, Cooldown: time.Minute}))
+	ts.FailNext(6, http.StatusServiceUnavailable)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Search(context.Background(), books.SearchParams{Query: "go"})
+		if !errors.Is(err, books.ErrUpstreamUnavailable) {
+			t.Fatalf("search %d: err = %v, want ErrUpstreamUnavailable", i, err)
+		}
+	}
+	if n := ts.Requests(); n != 6 {
+		t.Fatalf("requests = %d, want 6 (3 attempts per search)", n)
+	}
+	if st := c.BreakerState(); st != BreakerOpen {
+		t.Fatalf("breaker = %s, want open", st)
+	}
+
+	_, err := c.Search(context.Background(), books.SearchParams{Query: "go"})
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, books.ErrUpstreamUnavailable) {
+		t.Fatalf("err = %v, want an open circuit", err)
+	}
+	var ue *books.UpstreamError
+	if !errors.As(err, &ue) || ue.RetryAfter <= 0 {
+		t.Fatalf("open circuit should report Retry-After: %#v", err)
+	}
+	if n := ts.Requests(); n != 6 {
+		t.Fatalf("open breaker still called upstream: requests = %d", n)
+	}
+}
+
+// countingTransport counts the requests passed to the wrapped transport.
+type countingTransport struct {
+	next  http.RoundTripper
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	return t.next.RoundTrip(req)
+}
+
+func TestClientReplayMissIsNotRetried(t *testing.T) {
+	replay, err := cassette.New(t.TempDir(), cassette.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &countingTransport{next: replay}
+	c := NewClient("https://www.googleapis.com/books/v1/volumes", "")
+	c.WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	c.WithBreaker(NewBreaker(BreakerSettings{FailureThreshold: 1, Cooldown: time.Minute}))
+	c.WithTransport(transport)
+
+	_, err = c.Search(context.Background(), books.SearchParams{Query: "go"})
+	var nr *cassette.NotRecordedError
+	if !errors.Is(err, books.ErrUpstreamNotRecorded) || !errors.As(err, &nr) {
+		t.Fatalf("err = %v, want ErrUpstreamNotRecorded", err)
+	}
+	if errors.Is(err, books.ErrUpstreamUnavailable) {
+		t.Fatalf("replay miss must not look like an outage: %v", err)
+	}
+	if transport.calls != 1 {
+		t.Fatalf("requests = %d, want 1", transport.calls)
+	}
+	if st := c.BreakerState(); st != BreakerClosed {
+		t.Fatalf("breaker = %s, want closed", st)
+	}
+}
@@ -0,0 +1,92 @@
+package googlebooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/cassette"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// レスポンスボディの JSON を解釈できなかったときのエラー
+type payloadError struct {
+	err error
+}
+
+func (e *payloadError) Error() string { return "googlebooks decode response: " + e.err.Error() }
+func (e *payloadError) Unwrap() error { return e.err }
+
+// クライアント内部のエラーを books パッケージのエラー種別に変換する
+func (c *Client) classify(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return &books.UpstreamError{Kind: books.ErrCanceled, Err: err}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &books.UpstreamError{Kind: books.ErrUpstreamTimeout, Err: err}
+	case errors.Is(err, ErrCircuitOpen):
+		return &books.UpstreamError{Kind: books.ErrUpstreamUnavailable, RetryAfter: c.breaker.retryAfter(), Err: err}
+	}
+
+	var nr *cassette.NotRecordedError
+	if errors.As(err, &nr) {
+		return &books.UpstreamError{Kind: books.ErrUpstreamNotRecorded, Err: err}
+	}
+
+	var pe *payloadError
+	if errors.As(err, &pe) {
+		return &books.UpstreamError{Kind: books.ErrBadUpstreamPayload, Err: err}
+	}
+
+	var ue *upstreamIOError
+	if errors.As(err, &ue) {
+		var ne net.Error
+		if errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+			return &books.UpstreamError{Kind: books.ErrUpstreamTimeout, Err: err}
+		}
+		return &books.UpstreamError{Kind: books.ErrUpstreamUnavailable, Err: err}
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return &books.UpstreamError{Kind: statusKind(se), StatusCode: se.StatusCode, RetryAfter: se.RetryAfter, Err: err}
+	}
+	return &books.UpstreamError{Kind: books.ErrUpstream, Err: err}
+}
+
+func statusKind(se *statusError) error {
+	switch se.StatusCode {
+	case http.StatusTooManyRequests:
+		return books.ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// Google はクォータ超過も 403 で返す
+		if isQuotaError(se.Body) {
+			return books.ErrRateLimited
+		}
+		return books.ErrUpstreamUnauthorized
+	case http.StatusNotFound:
+		return books.ErrNotFound
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return books.ErrUpstreamTimeout
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return books.ErrUpstreamUnavailable
+	default:
+		return books.ErrUpstream
+	}
+}
+
+func isQuotaError(body string) bool {
+	for _, reason := range []string{"rateLimitExceeded", "userRateLimitExceeded", "dailyLimitExceeded", "quotaExceeded"} {
+		if strings.Contains(body, reason) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package fakeserver
+
+import (
+	"strings"
+	"unicode"
+)
+
+// term is one search word or phrase, optionally limited to a field and negated.
+type term struct {
+	field  string
+	text   string
+	negate bool
+}
+
+// clause matches when any of its terms matches (an OR group or a single term).
+type clause []term
+
+// query matches when every clause matches.
+type query []clause
+
+// parseQuery understands the subset of the Google Books query syntax the client sends:
+// plain words, "quoted phrases", -exclusions, intitle:/inauthor:/inpublisher:/subject:/isbn:
+// prefixes and OR, either between terms or inside parentheses.
+func parseQuery(q string) query {
+	var (
+		out     query
+		group   clause
+		inGroup bool
+		orNext  bool
+	)
+	add := func(t term) {
+		if inGroup {
+			group = append(group, t)
+			return
+		}
+		if orNext && len(out) > 0 {
+			out[len(out)-1] = append(out[len(out)-1], t)
+		} else {
+			out = append(out, clause{t})
+		}
+		orNext = false
+	}
+	for _, tok := range tokenize(q) {
+		switch tok {
+		case "(":
+			inGroup, group = true, nil
+			continue
+		case ")":
+			if inGroup && len(group) > 0 {
+				out = append(out, group)
+			}
+			inGroup, orNext = false, false
+			continue
+		case "OR", "|":
+			orNext = !inGroup
+			continue
+		}
+		t := term{text: tok}
+		if strings.HasPrefix(t.text, "-") && len(t.text) > 1 {
+			t.negate, t.text = true, t.text[1:]
+		}
+		if field, text, ok := strings.Cut(t.text, ":"); ok {
+			switch field {
+			case "intitle", "inauthor", "inpublisher", "subject", "isbn":
+				t.field, t.text = field, text
+			}
+		}
+		if t.text = strings.ToLower(strings.TrimSpace(t.text)); t.text != "" {
+			add(t)
+		}
+	}
+	return out
+}
+
+// tokenize splits q on whitespace, keeping quoted phrases (including a field prefix
+// such as intitle:"...") together and emitting parentheses as separate tokens.
+func tokenize(q string) []string {
+	var (
+		tokens []string
+		cur    strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	runes := []rune(q)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			cur.WriteString(string(runes[i+1 : min(end, len(runes))]))
+			i = end
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func (q query) match(v volumeInfo) bool {
+	for _, c := range q {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clause) match(v volumeInfo) bool {
+	for _, t := range c {
+		if t.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t term) match(v volumeInfo) bool {
+	var fields []string
+	switch t.field {
+	case "intitle":
+		fields = []string{v.Title, v.Subtitle}
+	case "inauthor":
+		fields = v.Authors
+	case "inpublisher":
+		fields = []string{v.Publisher}
+	case "subject":
+		fields = v.Categories
+	case "isbn":
+		text := strings.ReplaceAll(t.text, "-", "")
+		for _, id := range v.IndustryIdentifiers {
+			if strings.EqualFold(id.Identifier, text) {
+				return !t.negate
+			}
+		}
+		return t.negate
+	default:
+		fields = append([]string{v.Title, v.Subtitle, v.Publisher, v.Description}, v.Authors...)
+		fields = append(fields, v.Categories...)
+	}
+	for _, f := range fields {
+		if containsWord(strings.ToLower(f), t.text) {
+			return !t.negate
+		}
+	}
+	return t.negate
+}
+
+// containsWord reports whether text occurs in s without being part of a longer
+// ASCII word, so "go" does not match "google" but "go言語" still matches in Japanese text.
+func containsWord(s, text string) bool {
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], text)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(text)
+		if !asciiWord(s, start-1) && !asciiWord(s, end) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func asciiWord(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,341 @@
+// Package fakeserver is a local stand-in for the Google Books volumes API.
+// It serves a fixture corpus so googlebooks.Client and the search handler can be
+// exercised offline, and can inject latency, rate limiting and server errors.
+package fakeserver
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed corpus.json
+var defaultCorpus []byte
+
+// Fault describes errors injected into responses.
+type Fault struct {
+	// Status is the HTTP status returned for injected failures (429 or 5xx).
+	Status int
+	// Rate is the probability of failing a request, from 0 (never) to 1 (always).
+	Rate float64
+	// RetryAfter is sent as the Retry-After header of injected failures when positive.
+	RetryAfter time.Duration
+}
+
+// Options configures the fake server.
+type Options struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// Fault injects random failures.
+	Fault Fault
+	// Corpus is a JSON array of Google Books volumes; the embedded fixture is used when empty.
+	Corpus []byte
+}
+
+// Server implements the volumes search and volumes/{id} endpoints over a fixed corpus.
+type Server struct {
+	volumes []volume
+	byID    map[string]volume
+
+	mu       sync.Mutex
+	latency  time.Duration
+	fault    Fault
+	failNext []int
+	requests int
+}
+
+// volume keeps the raw JSON of a corpus entry next to the fields used for matching.
+type volume struct {
+	raw    json.RawMessage
+	id     string
+	info   volumeInfo
+	sale   saleInfo
+	access accessInfo
+}
+
+type volumeInfo struct {
+	Title               string   `json:"title"`
+	Subtitle            string   `json:"subtitle"`
+	Authors             []string `json:"authors"`
+	Publisher           string   `json:"publisher"`
+	PublishedDate       string   `json:"publishedDate"`
+	Description         string   `json:"description"`
+	Categories          []string `json:"categories"`
+	Language            string   `json:"language"`
+	IndustryIdentifiers []struct {
+		Identifier string `json:"identifier"`
+	} `json:"industryIdentifiers"`
+}
+
+type saleInfo struct {
+	Saleability string `json:"saleability"`
+	IsEbook     bool   `json:"isEbook"`
+}
+
+type accessInfo struct {
+	Viewability string `json:"viewability"`
+	Epub        struct {
+		IsAvailable bool `json:"isAvailable"`
+	} `json:"epub"`
+}
+
+// New creates a fake server from opts.
+func New(opts Options) (*Server, error) {
+	corpus := opts.Corpus
+	if len(corpus) == 0 {
+		corpus = defaultCorpus
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(corpus, &raws); err != nil {
+		return nil, fmt.Errorf("fakeserver: parse corpus: %w", err)
+	}
+	s := &Server{byID: make(map[string]volume, len(raws)), latency: opts.Latency, fault: opts.Fault}
+	for i, raw := range raws {
+		var v struct {
+			ID         string     `json:"id"`
+			VolumeInfo volumeInfo `json:"volumeInfo"`
+			SaleInfo   saleInfo   `json:"saleInfo"`
+			AccessInfo accessInfo `json:"accessInfo"`
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("fakeserver: parse corpus entry %d: %w", i, err)
+		}
+		if v.ID == "" {
+			return nil, fmt.Errorf("fakeserver: corpus entry %d has no id", i)
+		}
+		vol := volume{raw: raw, id: v.ID, info: v.VolumeInfo, sale: v.SaleInfo, access: v.AccessInfo}
+		s.volumes = append(s.volumes, vol)
+		s.byID[vol.id] = vol
+	}
+	return s, nil
+}
+
+// SetLatency changes the delay added to every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// SetFault changes the random failure injection.
+func (s *Server) SetFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = f
+}
+
+// FailNext makes the next n requests fail with status, ahead of any random fault.
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failNext = append(s.failNext, status)
+	}
+}
+
+// Requests returns the number of requests served so far.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// ServeHTTP serves .../volumes and .../volumes/{id}; any path prefix is accepted so the
+// server can stand in for https://www.googleapis.com/books/v1/volumes.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	latency, fail, retryAfter := s.begin()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fail != 0 {
+		if retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		}
+		writeError(w, fail, injectedMessage(fail), injectedReason(fail))
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed.", "methodNotAllowed")
+		return
+	}
+
+	path := strings.TrimRight(r.URL.Path, "/")
+	switch {
+	case strings.HasSuffix(path, "/volumes") || path == "volumes":
+		s.search(w, r)
+	case strings.Contains(path, "/volumes/"):
+		s.get(w, path[strings.LastIndex(path, "/volumes/")+len("/volumes/"):])
+	default:
+		writeError(w, http.StatusNotFound, "Not Found", "notFound")
+	}
+}
+
+// begin counts the request and decides its latency and injected failure.
+func (s *Server) begin() (time.Duration, int, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if len(s.failNext) > 0 {
+		status := s.failNext[0]
+		s.failNext = s.failNext[1:]
+		return s.latency, status, s.fault.RetryAfter
+	}
+	if s.fault.Status != 0 && s.fault.Rate > 0 && rand.Float64() < s.fault.Rate {
+		return s.latency, s.fault.Status, s.fault.RetryAfter
+	}
+	return s.latency, 0, 0
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "Missing query.", "queryRequired")
+		return
+	}
+	start, err := intParam(q.Get("startIndex"), 0)
+	if err != nil || start < 0 {
+		writeError(w, http.StatusBadRequest, "Invalid value at 'start_index'", "invalid")
+		return
+	}
+	max, err := intParam(q.Get("maxResults"), 10)
+	if err != nil || max < 0 || max > 40 {
+		writeError(w, http.StatusBadRequest, "Values must be within the range: [0, 40]", "invalid")
+		return
+	}
+	orderBy := q.Get("orderBy")
+	if orderBy != "" && orderBy != "relevance" && orderBy != "newest" {
+		writeError(w, http.StatusBadRequest, "Invalid value at 'order_by'", "invalid")
+		return
+	}
+
+	filter := q.Get("filter")
+	switch filter {
+	case "", "partial", "full", "free-ebooks", "paid-ebooks", "ebooks":
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid value at 'filter'", "invalid")
+		return
+	}
+	download := q.Get("download")
+	if download != "" && download != "epub" {
+		writeError(w, http.StatusBadRequest, "Invalid value at 'download'", "invalid")
+		return
+	}
+
+	clauses := parseQuery(query)
+	lang := strings.ToLower(q.Get("langRestrict"))
+	var hits []volume
+	for _, v := range s.volumes {
+		if lang != "" && !strings.EqualFold(v.info.Language, lang) {
+			continue
+		}
+		if !v.available(filter, download) {
+			continue
+		}
+		if clauses.match(v.info) {
+			hits = append(hits, v)
+		}
+	}
+	if orderBy == "newest" {
+		sort.SliceStable(hits, func(i, j int) bool {
+			return hits[i].info.PublishedDate > hits[j].info.PublishedDate
+		})
+	}
+
+	res := struct {
+		Kind       string            `json:"kind"`
+		TotalItems int               `json:"totalItems"`
+		Items      []json.RawMessage `json:"items,omitempty"`
+	}{Kind: "books#volumes", TotalItems: len(hits)}
+	for i := start; i < len(hits) && i < start+max; i++ {
+		res.Items = append(res.Items, hits[i].raw)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// available applies the filter and download parameters.
+func (v volume) available(filter, download string) bool {
+	if download == "epub" && !v.access.Epub.IsAvailable {
+		return false
+	}
+	switch filter {
+	case "partial":
+		return v.access.Viewability == "PARTIAL" || v.access.Viewability == "ALL_PAGES"
+	case "full":
+		return v.access.Viewability == "ALL_PAGES"
+	case "free-ebooks":
+		return v.sale.IsEbook && v.sale.Saleability == "FREE"
+	case "paid-ebooks":
+		return v.sale.IsEbook && v.sale.Saleability == "FOR_SALE"
+	case "ebooks":
+		return v.sale.IsEbook
+	}
+	return true
+}
+
+func (s *Server) get(w http.ResponseWriter, id string) {
+	v, ok := s.byID[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "The volume ID could not be found.", "notFound")
+		return
+	}
+	writeJSON(w, http.StatusOK, v.raw)
+}
+
+func intParam(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+func injectedMessage(status int) string {
+	if status == http.StatusTooManyRequests {
+		return "Quota exceeded for quota metric 'Queries' and limit 'Queries per minute'."
+	}
+	return http.StatusText(status)
+}
+
+func injectedReason(status int) string {
+	if status == http.StatusTooManyRequests {
+		return "rateLimitExceeded"
+	}
+	return "backendError"
+}
+
+// writeError writes an error body shaped like the Google APIs error format.
+func writeError(w http.ResponseWriter, status int, message, reason string) {
+	type detail struct {
+		Message string `json:"message"`
+		Domain  string `json:"domain"`
+		Reason  string `json:"reason"`
+	}
+	var body struct {
+		Error struct {
+			Code    int      `json:"code"`
+			Message string   `json:"message"`
+			Errors  []detail `json:"errors"`
+		} `json:"error"`
+	}
+	body.Error.Code = status
+	body.Error.Message = message
+	body.Error.Errors = []detail{{Message: message, Domain: "global", Reason: reason}}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,30 @@
+package fakeserver
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestServer is a fake server listening on a local httptest server.
+type TestServer struct {
+	*Server
+	HTTP *httptest.Server
+}
+
+// NewTestServer starts a fake server for a test and closes it when the test ends.
+// Pass BaseURL to googlebooks.NewClient (or set it as BOOKS_BASE_URL).
+func NewTestServer(tb testing.TB, opts Options) *TestServer {
+	tb.Helper()
+	s, err := New(opts)
+	if err != nil {
+		tb.Fatalf("fakeserver: %v", err)
+	}
+	ts := &TestServer{Server: s, HTTP: httptest.NewServer(s)}
+	tb.Cleanup(ts.HTTP.Close)
+	return ts
+}
+
+// BaseURL is the volumes endpoint of the test server.
+func (ts *TestServer) BaseURL() string {
+	return ts.HTTP.URL + "/books/v1/volumes"
+}
@@ -0,0 +1,56 @@
+package googlebooks
+
+import (
+	"strings"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/books/queryterm"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// 検索パラメータから Google Books の q を組み立てる。
+// 構造化フィールドは演算子付きの語として安全にエスケープし、自由入力の q はそのまま渡す。
+// 全角英数字などは NFKC で正規化してから組み立てる（Service を通さない呼び出しにも効かせるため）。
+func buildQuery(p books.SearchParams) string {
+	p = p.Normalize()
+	parts := []string{}
+	if len(p.TagQueries) > 0 {
+		// タグの語句は OR でまとめる
+		terms := make([]string, 0, len(p.TagQueries))
+		for _, t := range p.TagQueries {
+			if t = queryterm.Quote(t); t != "" {
+				terms = append(terms, t)
+			}
+		}
+		if len(terms) > 0 {
+			parts = append(parts, "("+strings.Join(terms, " OR ")+")")
+		}
+	}
+	if q := strings.TrimSpace(p.Query); q != "" {
+		parts = append(parts, q)
+	}
+
+	for _, f := range []struct {
+		op    string
+		value string
+	}{
+		{"intitle:", p.Title},
+		{"inauthor:", p.Author},
+		{"inpublisher:", p.Publisher},
+		{"subject:", p.Subject},
+		{"isbn:", p.ISBN},
+	} {
+		if t := queryterm.Quote(f.value); t != "" {
+			parts = append(parts, f.op+t)
+		}
+	}
+
+	if phrase := queryterm.Sanitize(p.ExactPhrase); phrase != "" {
+		parts = append(parts, `"`+phrase+`"`)
+	}
+	for _, ex := range p.Exclude {
+		if t := queryterm.Quote(ex); t != "" {
+			parts = append(parts, "-"+t)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,138 @@
+package googlebooks
+
+import (
+	"testing"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		params books.SearchParams
+		want   string
+	}{
+		{
+			name:   "free text is passed through",
+			params: books.SearchParams{Query: `golang "concurrency patterns" -java`},
+			want:   `golang "concurrency patterns" -java`,
+		},
+		{
+			name:   "single word field",
+			params: books.SearchParams{Title: "golang"},
+			want:   "intitle:golang",
+		},
+		{
+			name:   "multi-word field is quoted",
+			params: books.SearchParams{Author: "Rob  Pike"},
+			want:   `inauthor:"Rob Pike"`,
+		},
+		{
+			name:   "stray quotes are stripped",
+			params: books.SearchParams{Title: `the "go" book"`},
+			want:   `intitle:"the go book"`,
+		},
+		{
+			name:   "curly quotes are stripped",
+			params: books.SearchParams{Publisher: "“O'Reilly”"},
+			want:   "inpublisher:O'Reilly",
+		},
+		{
+			name:   "value of only quotes is dropped",
+			params: books.SearchParams{Title: `""`, Author: "“”"},
+			want:   "",
+		},
+		{
+			name:   "leading minus is quoted",
+			params: books.SearchParams{Title: "-go"},
+			want:   `intitle:"-go"`,
+		},
+		{
+			name:   "inner minus is kept as is",
+			params: books.SearchParams{Title: "go-kit"},
+			want:   "intitle:go-kit",
+		},
+		{
+			name:   "OR is quoted",
+			params: books.SearchParams{Subject: "OR"},
+			want:   `subject:"OR"`,
+		},
+		{
+			name:   "AND is quoted",
+			params: books.SearchParams{Subject: "AND"},
+			want:   `subject:"AND"`,
+		},
+		{
+			name:   "lone pipe is quoted",
+			params: books.SearchParams{Subject: "|"},
+			want:   `subject:"|"`,
+		},
+		{
+			name:   "embedded pipe is quoted",
+			params: books.SearchParams{Title: "a|b"},
+			want:   `intitle:"a|b"`,
+		},
+		{
+			name:   "plus is quoted",
+			params: books.SearchParams{Title: "C++"},
+			want:   `intitle:"C++"`,
+		},
+		{
+			name:   "parentheses are quoted",
+			params: books.SearchParams{Title: "Go(lang)"},
+			want:   `intitle:"Go(lang)"`,
+		},
+		{
+			name:   "colon is quoted",
+			params: books.SearchParams{Title: "intitle:go"},
+			want:   `intitle:"intitle:go"`,
+		},
+		{
+			name:   "exact phrase is always quoted",
+			params: books.SearchParams{ExactPhrase: `clean "code"`},
+			want:   `"clean code"`,
+		},
+		{
+			name:   "excludes are negated and quoted when needed",
+			params: books.SearchParams{Exclude: []string{"java", "visual basic", "-perl", `""`}},
+			want:   `-java -"visual basic" -"-perl"`,
+		},
+		{
+			name:   "tags are grouped with OR",
+			params: books.SearchParams{TagQueries: []string{"golang", "go programming", "“”"}},
+			want:   `(golang OR "go programming")`,
+		},
+		{
+			name: "everything combined in a fixed order",
+			params: books.SearchParams{
+				TagQueries:  []string{"golang", "go language"},
+				Query:       "web api",
+				Title:       "Go in Action",
+				Author:      "Kennedy",
+				Publisher:   "Manning",
+				Subject:     "Computers",
+				ISBN:        "9781617291784",
+				ExactPhrase: "net/http",
+				Exclude:     []string{"python"},
+			},
+			want: `(golang OR "go language") web api intitle:"Go in Action" inauthor:Kennedy inpublisher:Manning subject:Computers isbn:9781617291784 "net/http" -python`,
+		},
+		{
+			name:   "empty and blank fields are dropped",
+			params: books.SearchParams{Query: "  ", Title: " ", Author: "", Exclude: []string{" "}},
+			want:   "",
+		},
+		{
+			name:   "full-width input is normalized before escaping",
+			params: books.SearchParams{Title: "Ｇｏ　言語", Query: "ｇｏｌａｎｇ"},
+			want:   `golang intitle:"Go 言語"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildQuery(tt.params); got != tt.want {
+				t.Errorf("buildQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
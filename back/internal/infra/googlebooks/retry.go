@@ -0,0 +1,139 @@
+package googlebooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// リトライの設定
+type RetryPolicy struct {
+	// 最大試行回数（初回を含む）。1 ならリトライしない
+	MaxAttempts int
+	// 指数バックオフの基準待ち時間
+	BaseDelay time.Duration
+	// 1回あたりの待ち時間の上限。Retry-After がこれを超える場合はリトライしない
+	MaxDelay time.Duration
+}
+
+// デフォルトのリトライ設定
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    3 * time.Second,
+	}
+}
+
+// 上流が 2xx 以外を返したときのエラー
+type statusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("googlebooks upstream status %d: %s", e.StatusCode, e.Body)
+}
+
+// リトライ対象のエラーか判定する
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		switch se.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	// デコード失敗などは再試行しても結果が変わらない
+	var ue *upstreamIOError
+	return errors.As(err, &ue)
+}
+
+// サーキットブレーカーの失敗としてカウントするか判定する（上流の障害のみ）
+func breakerFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500
+	}
+	var ue *upstreamIOError
+	return errors.As(err, &ue)
+}
+
+// 通信そのものの失敗（接続エラー・タイムアウトなど）
+type upstreamIOError struct {
+	err error
+}
+
+func (e *upstreamIOError) Error() string { return e.err.Error() }
+func (e *upstreamIOError) Unwrap() error { return e.err }
+
+// attempt 回目（0 始まり）の失敗後に待つ時間を返す。ok=false ならリトライしない
+func (p RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	var se *statusError
+	if errors.As(err, &se) && se.RetryAfter > 0 {
+		if p.MaxDelay > 0 && se.RetryAfter > p.MaxDelay {
+			return 0, false
+		}
+		return se.RetryAfter, true
+	}
+
+	d := p.BaseDelay << attempt
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0, true
+	}
+	// equal jitter: d/2 + [0, d/2)
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1)), true
+}
+
+// Retry-After ヘッダ（秒数または HTTP-date）を解釈する
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
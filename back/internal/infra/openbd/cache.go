@@ -0,0 +1,85 @@
+package openbd
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// キャッシュの既定値。書誌情報はほとんど変わらないため長めに保持する
+const (
+	DefaultCacheTTL        = 24 * time.Hour
+	DefaultCacheMaxEntries = 10000
+)
+
+// ISBN ごとの検索結果を保持する TTL + LRU キャッシュ。
+// openBD に無い ISBN も「無い」ことを覚え、同じ ISBN を何度も問い合わせないようにする
+type isbnCache struct {
+	ttl time.Duration
+	max int
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	isbn      string
+	book      books.Book
+	found     bool
+	expiresAt time.Time
+}
+
+func newISBNCache(ttl time.Duration, maxEntries int) *isbnCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &isbnCache{
+		ttl:     ttl,
+		max:     maxEntries,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// キャッシュ済みの ISBN の結果を返す。cached が false ならまだ問い合わせていない
+func (c *isbnCache) get(isbn string) (b books.Book, found, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[isbn]
+	if !ok {
+		return books.Book{}, false, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !c.now().Before(e.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, isbn)
+		return books.Book{}, false, false
+	}
+	c.order.MoveToFront(el)
+	return e.book, e.found, true
+}
+
+func (c *isbnCache) put(isbn string, b books.Book, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &cacheEntry{isbn: isbn, book: b, found: found, expiresAt: c.now().Add(c.ttl)}
+	if el, ok := c.entries[isbn]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[isbn] = c.order.PushFront(e)
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).isbn)
+	}
+}
@@ -0,0 +1,218 @@
+package openbd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// openBD の get API が1回で受け付ける ISBN 数の目安
+const batchSize = 100
+
+// openBD（https://openbd.jp/）から ISBN をキーに書誌情報を取得するクライアント
+type Client struct {
+	baseURL string
+	http    *http.Client
+	cache   *isbnCache
+}
+
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://api.openbd.jp/v1"
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 3 * time.Second},
+		cache:   newISBNCache(DefaultCacheTTL, DefaultCacheMaxEntries),
+	}
+}
+
+// ISBN ごとの結果を保持する期間と件数を設定する（0 以下は既定値）
+func (c *Client) WithCache(ttl time.Duration, maxEntries int) {
+	c.cache = newISBNCache(ttl, maxEntries)
+}
+
+// キャッシュの時刻関数を差し替える（主にテスト用）
+func (c *Client) WithNow(fn func() time.Time) {
+	if fn != nil {
+		c.cache.now = fn
+	}
+}
+
+// HTTP のトランスポートを差し替える（録画・再生用。nil で既定に戻す）
+func (c *Client) WithTransport(rt http.RoundTripper) {
+	c.http.Transport = rt
+}
+
+// ISBN-13 の一覧に対応する書誌情報を返す。openBD に無い ISBN は結果に含まれない。
+// 一度問い合わせた ISBN はキャッシュから返し、未取得の ISBN だけを openBD に問い合わせる
+func (c *Client) LookupISBNs(ctx context.Context, isbns []string) (map[string]books.Book, error) {
+	out := make(map[string]books.Book, len(isbns))
+	var missing []string
+	for _, isbn := range isbns {
+		b, found, cached := c.cache.get(isbn)
+		switch {
+		case !cached:
+			missing = append(missing, isbn)
+		case found:
+			out[isbn] = b
+		}
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		batch := missing[start:min(start+batchSize, len(missing))]
+		records, err := c.get(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		fetched := make(map[string]books.Book, len(records))
+		for _, r := range records {
+			if r == nil {
+				continue
+			}
+			if isbn13, err := books.NormalizeISBN(r.Summary.ISBN); err == nil {
+				fetched[isbn13] = r.toBook()
+			}
+		}
+		for _, isbn := range batch {
+			b, found := fetched[isbn]
+			c.cache.put(isbn, b, found)
+			if found {
+				out[isbn] = b
+			}
+		}
+	}
+	return out, nil
+}
+
+func (c *Client) get(ctx context.Context, isbns []string) ([]*record, error) {
+	endpoint := c.baseURL + "/get?" + url.Values{"isbn": {strings.Join(isbns, ",")}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		return nil, fmt.Errorf("openbd upstream status %d: %s", res.StatusCode, strings.TrimSpace(string(b)))
+	}
+	var records []*record
+	if err := json.NewDecoder(res.Body).Decode(&records); err != nil {
+		return nil, fmt.Errorf("openbd decode response: %w", err)
+	}
+	return records, nil
+}
+
+// openBD レスポンスの構造体（必要な部分のみ）
+type record struct {
+	Summary struct {
+		ISBN      string `json:"isbn"`
+		Title     string `json:"title"`
+		Publisher string `json:"publisher"`
+		Pubdate   string `json:"pubdate"`
+		Cover     string `json:"cover"`
+		Author    string `json:"author"`
+	} `json:"summary"`
+	Onix struct {
+		CollateralDetail struct {
+			TextContent []struct {
+				TextType string `json:"TextType"`
+				Text     string `json:"Text"`
+			} `json:"TextContent"`
+		} `json:"CollateralDetail"`
+		DescriptiveDetail struct {
+			Extent []struct {
+				ExtentType  string `json:"ExtentType"`
+				ExtentValue string `json:"ExtentValue"`
+			} `json:"Extent"`
+		} `json:"DescriptiveDetail"`
+	} `json:"onix"`
+}
+
+func (r *record) toBook() books.Book {
+	b := books.Book{
+		Title:         r.Summary.Title,
+		Authors:       parseAuthors(r.Summary.Author),
+		Publisher:     r.Summary.Publisher,
+		PublishedDate: normalizePubdate(r.Summary.Pubdate),
+		Thumbnail:     r.Summary.Cover,
+		Language:      "ja",
+	}
+	if d, err := books.ParseDate(b.PublishedDate); err == nil {
+		b.Published = &d
+	}
+	// TextType 03 = 内容紹介（長）, 02 = 内容紹介（短）
+	for _, want := range []string{"03", "02"} {
+		for _, t := range r.Onix.CollateralDetail.TextContent {
+			if t.TextType == want && b.Description == "" {
+				b.Description = strings.TrimSpace(t.Text)
+			}
+		}
+	}
+	// ExtentType 11 = 本文ページ数
+	for _, e := range r.Onix.DescriptiveDetail.Extent {
+		if e.ExtentType == "11" {
+			fmt.Sscanf(e.ExtentValue, "%d", &b.PageCount)
+		}
+	}
+	return b
+}
+
+// "著者A／著 著者B／訳" のような表記から「著」の役割の名前だけを取り出す。
+// 名前自体に空白（全角を含む）が入ることがあるため、区切りは "／役割" の後ろの空白とする。
+// 役割の表記が無い場合は全体を1人の著者とみなす
+func parseAuthors(raw string) []string {
+	rest := strings.TrimSpace(raw)
+	if !strings.Contains(rest, "／") {
+		if rest == "" {
+			return nil
+		}
+		return []string{rest}
+	}
+	var out []string
+	for rest != "" {
+		name, after, ok := strings.Cut(rest, "／")
+		if !ok {
+			break // 役割の無い末尾は著者か判断できない
+		}
+		role, next := after, ""
+		if i := strings.IndexFunc(after, unicode.IsSpace); i >= 0 {
+			role, next = after[:i], after[i:]
+		}
+		if name = strings.TrimSpace(name); name != "" && role == "著" {
+			out = append(out, name)
+		}
+		rest = strings.TrimSpace(next)
+	}
+	return out
+}
+
+// "20190315" / "201903" / "2019" / "2019-03-15" を YYYY[-MM[-DD]] にそろえる
+func normalizePubdate(raw string) string {
+	s := strings.ReplaceAll(strings.TrimSpace(raw), "-", "")
+	switch len(s) {
+	case 8:
+		return s[:4] + "-" + s[4:6] + "-" + s[6:]
+	case 6:
+		return s[:4] + "-" + s[4:]
+	case 4:
+		return s
+	default:
+		return ""
+	}
+}
+
+var _ books.MetadataProvider = (*Client)(nil)
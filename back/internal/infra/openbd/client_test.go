@@ -0,0 +1,171 @@
+package openbd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// openBD に登録済みの書籍（get API のレスポンス形式）
+var fakeRecords = map[string]string{
+	"9784048930598": `{
+		"summary": {
+			"isbn": "9784048930598",
+			"title": "Clean Architecture",
+			"publisher": "KADOKAWA",
+			"pubdate": "20180727",
+			"cover": "https://cover.openbd.jp/9784048930598.jpg",
+			"author": "Ｒｏｂｅｒｔ　Ｃ．Ｍａｒｔｉｎ／著 角征典／訳 高木正弘／訳"
+		},
+		"onix": {
+			"CollateralDetail": {"TextContent": [
+				{"TextType": "02", "Text": "短い紹介"},
+				{"TextType": "03", "Text": " 長い紹介 "}
+			]},
+			"DescriptiveDetail": {"Extent": [{"ExtentType": "11", "ExtentValue": "352"}]}
+		}
+	}`,
+	"9784873115658": `{"summary": {"isbn": "978-4-87311-565-8", "title": "リーダブルコード", "pubdate": "201206", "author": "Dustin Boswell／著 Trevor Foucher／著 角征典／訳"}}`,
+}
+
+// fakeServer は openBD の get API を模倣し、受けたリクエスト数を数える
+func fakeServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/get" {
+			http.NotFound(w, r)
+			return
+		}
+		var out []json.RawMessage
+		for _, isbn := range strings.Split(r.URL.Query().Get("isbn"), ",") {
+			if rec, ok := fakeRecords[isbn]; ok {
+				out = append(out, json.RawMessage(rec))
+			} else {
+				out = append(out, json.RawMessage("null"))
+			}
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestLookupISBNs(t *testing.T) {
+	srv, _ := fakeServer(t)
+	c := NewClient(srv.URL)
+
+	got, err := c.LookupISBNs(context.Background(), []string{"9784048930598", "9784873115658", "9780000000002"})
+	if err != nil {
+		t.Fatalf("LookupISBNs: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d books, want 2 (unknown ISBN omitted)", len(got))
+	}
+
+	b := got["9784048930598"]
+	if b.Title != "Clean Architecture" || b.Publisher != "KADOKAWA" || b.Language != "ja" {
+		t.Errorf("summary = %q %q %q", b.Title, b.Publisher, b.Language)
+	}
+	if !reflect.DeepEqual(b.Authors, []string{"Ｒｏｂｅｒｔ　Ｃ．Ｍａｒｔｉｎ"}) {
+		t.Errorf("Authors = %q, want the author only (no translators)", b.Authors)
+	}
+	if b.PublishedDate != "2018-07-27" || b.Published == nil || b.Published.Day != 27 {
+		t.Errorf("PublishedDate = %q (%+v), want 2018-07-27", b.PublishedDate, b.Published)
+	}
+	if b.Description != "長い紹介" || b.PageCount != 352 {
+		t.Errorf("Description/PageCount = %q %d, want the long description and 352 pages", b.Description, b.PageCount)
+	}
+
+	b = got["9784873115658"]
+	if !reflect.DeepEqual(b.Authors, []string{"Dustin Boswell", "Trevor Foucher"}) || b.PublishedDate != "2012-06" {
+		t.Errorf("Authors/PublishedDate = %q %q", b.Authors, b.PublishedDate)
+	}
+}
+
+func TestLookupISBNsCachesResultsAndMisses(t *testing.T) {
+	srv, requests := fakeServer(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClient(srv.URL)
+	c.WithCache(time.Hour, 10)
+	c.WithNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	isbns := []string{"9784048930598", "9780000000002"}
+	if _, err := c.LookupISBNs(ctx, isbns); err != nil {
+		t.Fatalf("LookupISBNs: %v", err)
+	}
+	got, err := c.LookupISBNs(ctx, isbns)
+	if err != nil {
+		t.Fatalf("LookupISBNs: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1 (found and missing ISBNs are cached)", n)
+	}
+	if _, ok := got["9784048930598"]; !ok || len(got) != 1 {
+		t.Errorf("cached result = %v, want only the known ISBN", got)
+	}
+
+	// 未取得の ISBN だけを問い合わせる
+	if _, err := c.LookupISBNs(ctx, []string{"9784048930598", "9784873115658"}); err != nil {
+		t.Fatalf("LookupISBNs: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := c.LookupISBNs(ctx, isbns); err != nil {
+		t.Fatalf("LookupISBNs: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3 (entries expire after the TTL)", n)
+	}
+}
+
+func TestLookupISBNsDoesNotCacheErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.LookupISBNs(context.Background(), []string{"9784048930598"}); err == nil || !strings.Contains(err.Error(), "503") {
+			t.Fatalf("LookupISBNs error = %v, want upstream status 503", err)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2 (errors are not cached)", n)
+	}
+}
+
+func TestParseAuthors(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"結城浩／著", []string{"結城浩"}},
+		{"Ｒｏｂｅｒｔ　Ｃ．Ｍａｒｔｉｎ／著 角征典／訳 高木正弘／訳", []string{"Ｒｏｂｅｒｔ　Ｃ．Ｍａｒｔｉｎ"}},
+		{"Robert C. Martin／著 角 征典／訳", []string{"Robert C. Martin"}},
+		{"山田太郎／著　鈴木花子／著", []string{"山田太郎", "鈴木花子"}},
+		{"技術評論社編集部／編", nil},
+		{"結城浩／著 末尾", []string{"結城浩"}},
+		{"結城浩", []string{"結城浩"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := parseAuthors(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAuthors(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package openlibrary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/books/queryterm"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/infra/cassette"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// Open Library の書籍 ID に付ける接頭辞（Google Books の ID と区別するため）
+const IDPrefix = "ol:"
+
+const coverURL = "https://covers.openlibrary.org/b/id/%d-M.jpg"
+
+// Open Library（https://openlibrary.org/）の検索 API を books.ExternalClient として使うクライアント
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://openlibrary.org"
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// HTTP のトランスポートを差し替える（録画・再生用。nil で既定に戻す）
+func (c *Client) WithTransport(rt http.RoundTripper) {
+	c.http.Transport = rt
+}
+
+func (c *Client) Search(ctx context.Context, params books.SearchParams) (books.SearchResult, error) {
+	values := url.Values{}
+	for key, v := range map[string]string{
+		"title":     params.Title,
+		"author":    params.Author,
+		"publisher": params.Publisher,
+		"subject":   params.Subject,
+		"isbn":      params.ISBN,
+	} {
+		if v = strings.TrimSpace(v); v != "" {
+			values.Set(key, v)
+		}
+	}
+	// 電子書籍の販売・閲覧範囲の情報は Open Library に無いため、その絞り込みには参加しない。
+	// 対応する言語コードが無い言語の指定も、全言語の結果を返さないよう参加しない
+	_, known := languages[params.Lang]
+	unknownLang := !known && params.Lang != "" && params.Lang != "all"
+	noCondition := len(values) == 0 && len(queryTerms(params)) == 0
+	if noCondition || unknownLang || params.EbookFilter != "" || params.Download != "" {
+		return books.SearchResult{}, nil
+	}
+	values.Set("q", buildQuery(params))
+	if params.OrderBy == "newest" {
+		values.Set("sort", "new")
+	}
+	limit := params.MaxResults
+	if limit <= 0 {
+		limit = 10
+	}
+	values.Set("offset", strconv.Itoa(params.StartIndex))
+	values.Set("limit", strconv.Itoa(limit))
+	values.Set("fields", searchFields)
+
+	var res searchResponse
+	if err := c.getJSON(ctx, "/search.json?"+values.Encode(), &res); err != nil {
+		return books.SearchResult{}, err
+	}
+	items := make([]books.Book, 0, len(res.Docs))
+	for _, d := range res.Docs {
+		items = append(items, d.toBook())
+	}
+	return books.SearchResult{TotalItems: res.NumFound, Items: items}, nil
+}
+
+// "ol:OL123W" 形式の ID で作品を取得する
+func (c *Client) Get(ctx context.Context, id string) (books.Book, error) {
+	key, ok := strings.CutPrefix(id, IDPrefix)
+	if !ok || key == "" {
+		return books.Book{}, books.ErrNotFound
+	}
+	var w work
+	if err := c.getJSON(ctx, "/works/"+url.PathEscape(key)+".json", &w); err != nil {
+		return books.Book{}, err
+	}
+	return w.toBook(id), nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return classify(ctx, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 4<<10))
+		err := fmt.Errorf("openlibrary upstream status %d: %s", res.StatusCode, strings.TrimSpace(string(b)))
+		return &books.UpstreamError{Kind: statusKind(res.StatusCode), StatusCode: res.StatusCode, Err: err}
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return &books.UpstreamError{Kind: books.ErrBadUpstreamPayload, Err: fmt.Errorf("openlibrary decode response: %w", err)}
+	}
+	return nil
+}
+
+func classify(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return &books.UpstreamError{Kind: books.ErrCanceled, Err: err}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &books.UpstreamError{Kind: books.ErrUpstreamTimeout, Err: err}
+	}
+	var nr *cassette.NotRecordedError
+	if errors.As(err, &nr) {
+		return &books.UpstreamError{Kind: books.ErrUpstreamNotRecorded, Err: err}
+	}
+	var ne interface{ Timeout() bool }
+	if errors.As(err, &ne) && ne.Timeout() {
+		return &books.UpstreamError{Kind: books.ErrUpstreamTimeout, Err: err}
+	}
+	return &books.UpstreamError{Kind: books.ErrUpstreamUnavailable, Err: err}
+}
+
+func statusKind(code int) error {
+	switch {
+	case code == http.StatusNotFound:
+		return books.ErrNotFound
+	case code == http.StatusTooManyRequests:
+		return books.ErrRateLimited
+	case code == http.StatusGatewayTimeout || code == http.StatusRequestTimeout:
+		return books.ErrUpstreamTimeout
+	case code >= 500:
+		return books.ErrUpstreamUnavailable
+	default:
+		return books.ErrUpstream
+	}
+}
+
+// 自由入力・タグ・完全一致・除外語・言語を Open Library の q にまとめる。
+// 構造化フィールド（title など）は専用のパラメータで渡すため、言語は他の語が無くても付ける
+func buildQuery(p books.SearchParams) string {
+	parts := queryTerms(p)
+	if lang, ok := languages[p.Lang]; ok {
+		parts = append(parts, "language:"+lang)
+	}
+	return strings.Join(parts, " ")
+}
+
+// q に入れる検索語（言語を除く）
+func queryTerms(p books.SearchParams) []string {
+	var parts []string
+	if len(p.TagQueries) > 0 {
+		terms := make([]string, 0, len(p.TagQueries))
+		for _, t := range p.TagQueries {
+			if t = queryterm.Quote(t); t != "" {
+				terms = append(terms, t)
+			}
+		}
+		if len(terms) > 0 {
+			parts = append(parts, "("+strings.Join(terms, " OR ")+")")
+		}
+	}
+	if q := strings.TrimSpace(p.Query); q != "" {
+		parts = append(parts, q)
+	}
+	if phrase := queryterm.Sanitize(p.ExactPhrase); phrase != "" {
+		parts = append(parts, `"`+phrase+`"`)
+	}
+	for _, ex := range p.Exclude {
+		if t := queryterm.Quote(ex); t != "" {
+			parts = append(parts, "-"+t)
+		}
+	}
+	return parts
+}
+
+// Google Books の langRestrict（ISO 639-1）と Open Library の言語コード（MARC）の対応
+var languages = map[string]string{
+	"ja": "jpn",
+	"en": "eng",
+	"zh": "chi",
+	"ko": "kor",
+	"de": "ger",
+	"fr": "fre",
+	"es": "spa",
+}
+
+// 言語コードを ISO 639-1 に戻す（対応表に無いものは空）
+func languageCode(marc string) string {
+	for iso, m := range languages {
+		if m == marc {
+			return iso
+		}
+	}
+	return ""
+}
+
+const searchFields = "key,title,subtitle,author_name,publisher,first_publish_year,publish_date,isbn,subject,number_of_pages_median,cover_i,language,ratings_average,ratings_count"
+
+// Open Library レスポンスの構造体（必要な部分のみ）
+type searchResponse struct {
+	NumFound int   `json:"numFound"`
+	Docs     []doc `json:"docs"`
+}
+
+type doc struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	Subtitle         string   `json:"subtitle"`
+	AuthorName       []string `json:"author_name"`
+	Publisher        []string `json:"publisher"`
+	FirstPublishYear int      `json:"first_publish_year"`
+	ISBN             []string `json:"isbn"`
+	Subject          []string `json:"subject"`
+	Pages            int      `json:"number_of_pages_median"`
+	CoverID          int      `json:"cover_i"`
+	Language         []string `json:"language"`
+	RatingsAverage   float64  `json:"ratings_average"`
+	RatingsCount     int      `json:"ratings_count"`
+}
+
+func (d doc) toBook() books.Book {
+	b := books.Book{
+		ID:            IDPrefix + strings.TrimPrefix(d.Key, "/works/"),
+		Title:         d.Title,
+		Subtitle:      d.Subtitle,
+		Authors:       d.AuthorName,
+		PageCount:     d.Pages,
+		AverageRating: d.RatingsAverage,
+		RatingsCount:  d.RatingsCount,
+		InfoLink:      "https://openlibrary.org" + d.Key,
+	}
+	if len(d.Publisher) > 0 {
+		b.Publisher = d.Publisher[0]
+	}
+	if d.FirstPublishYear > 0 {
+		b.PublishedDate = strconv.Itoa(d.FirstPublishYear)
+		if date, err := books.ParseDate(b.PublishedDate); err == nil {
+			b.Published = &date
+		}
+	}
+	if len(d.Subject) > 0 {
+		b.Categories = d.Subject[:min(len(d.Subject), 5)]
+	}
+	if d.CoverID > 0 {
+		b.Thumbnail = fmt.Sprintf(coverURL, d.CoverID)
+	}
+	if len(d.Language) == 1 {
+		b.Language = languageCode(d.Language[0])
+	}
+	// 複数の版の ISBN が混ざるため、最初に見つかった正しい ISBN を代表とする
+	for _, raw := range d.ISBN {
+		if isbn13, err := books.NormalizeISBN(raw); err == nil {
+			b.ISBN13 = isbn13
+			break
+		}
+	}
+	return b
+}
+
+type work struct {
+	Key              string          `json:"key"`
+	Title            string          `json:"title"`
+	Subtitle         string          `json:"subtitle"`
+	Description      json.RawMessage `json:"description"`
+	Subjects         []string        `json:"subjects"`
+	Covers           []int           `json:"covers"`
+	FirstPublishDate string          `json:"first_publish_date"`
+}
+
+func (w work) toBook(id string) books.Book {
+	b := books.Book{
+		ID:            id,
+		Title:         w.Title,
+		Subtitle:      w.Subtitle,
+		Description:   description(w.Description),
+		PublishedDate: w.FirstPublishDate,
+		InfoLink:      "https://openlibrary.org" + w.Key,
+	}
+	if len(w.Subjects) > 0 {
+		b.Categories = w.Subjects[:min(len(w.Subjects), 5)]
+	}
+	if len(w.Covers) > 0 && w.Covers[0] > 0 {
+		b.Thumbnail = fmt.Sprintf(coverURL, w.Covers[0])
+	}
+	if d, err := books.ParseDate(b.PublishedDate); err == nil {
+		b.Published = &d
+	}
+	return b
+}
+
+// description は文字列か {"type": ..., "value": ...} のどちらか
+func description(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return v.Value
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package openlibrary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		p    books.SearchParams
+		want string
+	}{
+		{"query", books.SearchParams{Query: "golang"}, "golang"},
+		{"query and language", books.SearchParams{Query: "golang", Lang: "ja"}, "golang language:jpn"},
+		{"language only", books.SearchParams{Title: "Go", Lang: "en"}, "language:eng"},
+		{"all languages", books.SearchParams{Query: "golang", Lang: "all"}, "golang"},
+		{"tags and exclusions", books.SearchParams{TagQueries: []string{"Go", "data structures"}, Exclude: []string{"python"}}, `(Go OR "data structures") -python`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildQuery(tt.p); got != tt.want {
+				t.Errorf("buildQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRestrictsStructuredSearchesToLanguage(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get("q")+" title="+r.URL.Query().Get("title"))
+		w.Write([]byte(`{"numFound": 0, "docs": []}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+
+	if _, err := c.Search(ctx, books.SearchParams{Title: "Go", Lang: "ja", MaxResults: 10}); err != nil {
+		t.Fatal(err)
+	}
+	// Open Library に対応する言語コードが無い言語、条件が言語だけの検索は問い合わせない
+	for _, p := range []books.SearchParams{{Title: "Go", Lang: "it"}, {Lang: "ja"}} {
+		if res, err := c.Search(ctx, p); err != nil || len(res.Items) != 0 {
+			t.Fatalf("Search(%+v) = %v, %v", p, res, err)
+		}
+	}
+	if len(got) != 1 || got[0] != "language:jpn title=Go" {
+		t.Errorf("requests = %q, want one structured search restricted to jpn", got)
+	}
+}
@@ -0,0 +1,180 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/searches"
+)
+
+// Repository persists search history and saved searches on the local filesystem as JSON.
+type Repository struct {
+	path string
+	mu   sync.Mutex
+}
+
+type store struct {
+	History []searches.HistoryEntry         `json:"history"`
+	Saved   map[string]searches.SavedSearch `json:"saved"`
+}
+
+// New creates a file-backed repository for searches.
+func New(path string) (*Repository, error) {
+	if path == "" {
+		return nil, fmt.Errorf("filestore path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(path, []byte(`{"history":[],"saved":{}}`), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return &Repository{path: path}, nil
+}
+
+func (r *Repository) AppendHistory(_ context.Context, entry searches.HistoryEntry, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return err
+	}
+	st.History = append(st.History, entry)
+	if limit > 0 && len(st.History) > limit {
+		st.History = st.History[len(st.History)-limit:]
+	}
+	return r.persist(st)
+}
+
+func (r *Repository) ListHistory(_ context.Context) ([]searches.HistoryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]searches.HistoryEntry, 0, len(st.History))
+	for i := len(st.History) - 1; i >= 0; i-- {
+		entries = append(entries, st.History[i])
+	}
+	return entries, nil
+}
+
+func (r *Repository) ClearHistory(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return err
+	}
+	st.History = nil
+	return r.persist(st)
+}
+
+func (r *Repository) GetSaved(_ context.Context, id string) (searches.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return searches.SavedSearch{}, err
+	}
+	s, ok := st.Saved[id]
+	if !ok {
+		return searches.SavedSearch{}, searches.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *Repository) UpsertSaved(_ context.Context, s searches.SavedSearch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return err
+	}
+	st.Saved[s.ID] = s
+	return r.persist(st)
+}
+
+func (r *Repository) DeleteSaved(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return err
+	}
+	delete(st.Saved, id)
+	return r.persist(st)
+}
+
+func (r *Repository) ListSaved(_ context.Context) ([]searches.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]searches.SavedSearch, 0, len(st.Saved))
+	for _, s := range st.Saved {
+		items = append(items, s)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (r *Repository) load() (store, error) {
+	bytes, err := os.ReadFile(r.path)
+	if err != nil {
+		return store{}, err
+	}
+	var st store
+	if len(bytes) > 0 {
+		if err := json.Unmarshal(bytes, &st); err != nil {
+			return store{}, err
+		}
+	}
+	if st.Saved == nil {
+		st.Saved = make(map[string]searches.SavedSearch)
+	}
+	return st, nil
+}
+
+func (r *Repository) persist(st store) error {
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), "searches-*.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(st); err != nil {
+		tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+var _ searches.Repository = (*Repository)(nil)
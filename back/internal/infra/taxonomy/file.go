@@ -0,0 +1,37 @@
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+type fileTag struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	Queries []string `json:"queries"`
+}
+
+// LoadFile reads a tag taxonomy from a JSON file shaped like
+// [{"key": "network", "label": "Network", "queries": ["computer networks"]}].
+func LoadFile(path string) (*books.Taxonomy, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw []fileTag
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return nil, fmt.Errorf("parse taxonomy %s: %w", path, err)
+	}
+	tags := make([]books.Tag, 0, len(raw))
+	for _, t := range raw {
+		tags = append(tags, books.Tag{Key: t.Key, Label: t.Label, Queries: t.Queries})
+	}
+	t, err := books.NewTaxonomy(tags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid taxonomy %s: %w", path, err)
+	}
+	return t, nil
+}
@@ -9,8 +9,16 @@ import (
 	"github.com/recursion-goapi-project/technical-books-search/back/internal/handler"
 )
 
+// BooksHandlers groups the HTTP handlers for the books endpoints.
+type BooksHandlers struct {
+	Search     http.HandlerFunc
+	Get        http.HandlerFunc
+	LookupISBN http.HandlerFunc
+	ListTags   http.HandlerFunc
+}
+
 // NewRouter creates and configures the main HTTP router with all endpoints and middleware.
-func NewRouter(searchBooksHandler http.HandlerFunc, tsundokuHandler *handler.TsundokuHandler, favoritesHandler *handler.FavoritesHandler) *chi.Mux {
+func NewRouter(booksHandlers BooksHandlers, tsundokuHandler *handler.TsundokuHandler, favoritesHandler *handler.FavoritesHandler, searchesHandler *handler.SearchesHandler, healthHandler http.HandlerFunc) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Apply middleware
@@ -18,12 +26,16 @@ func NewRouter(searchBooksHandler http.HandlerFunc, tsundokuHandler *handler.Tsu
 	r.Use(corsMiddleware)       // Enable CORS for frontend
 
 	// Health check endpoint
-	r.Get("/health", handler.HealthCheckHandler)
+	r.Get("/health", healthHandler)
 
 	// API routes
-	r.Get("/api/technical-books", searchBooksHandler)
+	r.Get("/api/technical-books", booksHandlers.Search)
+	r.Get("/api/technical-books/{id}", booksHandlers.Get)
+	r.Get("/api/books/isbn/{isbn}", booksHandlers.LookupISBN)
+	r.Get("/api/tags", booksHandlers.ListTags)
 	r.Route("/api/tsundoku", tsundokuHandler.Register)
 	r.Route("/api/favorites", favoritesHandler.Register)
+	r.Route("/api/searches", searchesHandler.Register)
 
 	return r
 }
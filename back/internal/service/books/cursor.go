@@ -0,0 +1,99 @@
+package books
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// 改ざん・破損したカーソル
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// カーソルに保持する重複排除用 ID ハッシュの上限
+const maxSeenIDs = 200
+
+// 検索の続きを表す状態。クライアントには署名付きの不透明な文字列として渡す
+type Cursor struct {
+	Params SearchParams `json:"p"`
+	Offset int          `json:"o"`
+	Seen   []string     `json:"s,omitempty"`
+	// 複数言語の検索で、次に各言語を読み始める位置と、分かっている各言語の件数
+	Langs map[string]int `json:"l,omitempty"`
+	Ends  map[string]int `json:"e,omitempty"`
+}
+
+// カーソルの署名・検証を行う
+type CursorCodec struct {
+	secret []byte
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: append([]byte(nil), secret...)}
+}
+
+// カーソルを "payload.signature"（どちらも base64url）に変換する
+func (c *CursorCodec) Encode(cur Cursor) (string, error) {
+	payload, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// 署名を検証してカーソルを復元する
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var cur Cursor
+	if err := json.Unmarshal(payload, &cur); err != nil || cur.Offset < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	for _, m := range []map[string]int{cur.Langs, cur.Ends} {
+		for _, v := range m {
+			if v < 0 {
+				return Cursor{}, ErrInvalidCursor
+			}
+		}
+	}
+	return cur, nil
+}
+
+// カーソルの内容を検索パラメータに戻す
+func (cur Cursor) SearchParams() SearchParams {
+	p := cur.Params
+	p.StartIndex = cur.Offset
+	p.SeenIDs = cur.Seen
+	p.LangOffsets = cur.Langs
+	p.LangEnds = cur.Ends
+	return p
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// カーソルに載せる短い ID ハッシュ
+func seenHash(id string) string {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return strconv.FormatUint(h.Sum64()&0xffffffff, 36)
+}
@@ -0,0 +1,77 @@
+package books
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	cur := Cursor{
+		Params: SearchParams{Query: "golang", MaxResults: 20, Langs: []string{"ja", "en"}},
+		Offset: 40,
+		Seen:   []string{seenHash("a"), seenHash("b")},
+	}
+	token, err := codec.Encode(cur)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := codec.Decode(token)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, cur) {
+		t.Errorf("Decode = %+v, want %+v", got, cur)
+	}
+
+	p := got.SearchParams()
+	if p.StartIndex != 40 || !reflect.DeepEqual(p.SeenIDs, cur.Seen) {
+		t.Errorf("SearchParams = start %d seen %v, want start 40 seen %v", p.StartIndex, p.SeenIDs, cur.Seen)
+	}
+}
+
+func TestCursorDecodeRejectsInvalidTokens(t *testing.T) {
+	codec := NewCursorCodec([]byte("secret"))
+	enc := base64.RawURLEncoding
+	valid, err := codec.Encode(Cursor{Params: SearchParams{Query: "golang"}, Offset: 20})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	payload, sig, _ := strings.Cut(valid, ".")
+
+	// 署名は正しいが内容が不正なカーソル
+	signed := func(raw string) string {
+		return enc.EncodeToString([]byte(raw)) + "." + enc.EncodeToString(codec.sign([]byte(raw)))
+	}
+	other, err := NewCursorCodec([]byte("other")).Encode(Cursor{Params: SearchParams{Query: "golang"}, Offset: 20})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"missing separator", payload + sig},
+		{"tampered payload", enc.EncodeToString([]byte(`{"p":{"Query":"golang"},"o":40}`)) + "." + sig},
+		{"tampered signature", payload + "." + enc.EncodeToString([]byte("0123456789abcdef"))},
+		{"truncated signature", payload + "." + sig[:len(sig)-2]},
+		{"not base64", "!!!." + sig},
+		{"signed with another secret", other},
+		{"negative offset", signed(`{"p":{},"o":-20}`)},
+		{"negative language offset", signed(`{"p":{},"o":0,"l":{"ja":-1}}`)},
+		{"negative language end", signed(`{"p":{},"o":0,"e":{"ja":-3}}`)},
+		{"not json", signed("golang")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) error = %v, want ErrInvalidCursor", tt.token, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package books
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 日付として解釈できない入力
+var ErrInvalidDate = errors.New("invalid date")
+
+// 日付の精度
+type DatePrecision string
+
+const (
+	PrecisionYear  DatePrecision = "year"
+	PrecisionMonth DatePrecision = "month"
+	PrecisionDay   DatePrecision = "day"
+)
+
+// 精度付きの出版日（"2001" / "2001-01" / "2001-01-10"）
+type Date struct {
+	Year      int
+	Month     int           `json:"Month,omitempty"`
+	Day       int           `json:"Day,omitempty"`
+	Precision DatePrecision // year | month | day
+}
+
+// Google Books の publishedDate 形式の文字列を解釈する。
+// 時刻付き（"2001-01-10T00:00:00Z"）や末尾の "*"（推定年）も受け付ける
+func ParseDate(raw string) (Date, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(raw), "*")
+	if i := strings.IndexByte(s, 'T'); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) == 0 || len(parts) > 3 || len(parts[0]) != 4 {
+		return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, raw)
+	}
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, raw)
+		}
+		nums[i] = n
+	}
+
+	d := Date{Year: nums[0], Precision: PrecisionYear}
+	if len(nums) >= 2 {
+		if nums[1] < 1 || nums[1] > 12 {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, raw)
+		}
+		d.Month, d.Precision = nums[1], PrecisionMonth
+	}
+	if len(nums) == 3 {
+		t := time.Date(d.Year, time.Month(d.Month), nums[2], 0, 0, 0, 0, time.UTC)
+		if nums[2] < 1 || t.Day() != nums[2] {
+			return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, raw)
+		}
+		d.Day, d.Precision = nums[2], PrecisionDay
+	}
+	return d, nil
+}
+
+// 精度に応じた文字列表現
+func (d Date) String() string {
+	switch d.Precision {
+	case PrecisionDay:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	case PrecisionMonth:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	default:
+		return fmt.Sprintf("%04d", d.Year)
+	}
+}
+
+// 日付が表す期間の初日
+func (d Date) Start() time.Time {
+	month, day := max(d.Month, 1), max(d.Day, 1)
+	return time.Date(d.Year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// 日付が表す期間の最終日
+func (d Date) End() time.Time {
+	start := d.Start()
+	switch d.Precision {
+	case PrecisionDay:
+		return start
+	case PrecisionMonth:
+		return start.AddDate(0, 1, -1)
+	default:
+		return start.AddDate(1, 0, -1)
+	}
+}
+
+// 保存済みの JSON に Published が無い場合は PublishedDate から補う
+func (b *Book) UnmarshalJSON(data []byte) error {
+	type plain Book
+	var v plain
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = Book(v)
+	b.normalizePublished()
+	return nil
+}
+
+// PublishedDate から正規化済みの Published を設定する（解釈できなければ nil）
+func (b *Book) normalizePublished() {
+	if b.Published != nil || b.PublishedDate == "" {
+		return
+	}
+	if d, err := ParseDate(b.PublishedDate); err == nil {
+		b.Published = &d
+	}
+}
+
+// 出版日の範囲フィルタ。両端を含み、出版日が不明な書籍は除く
+func publishedRangeFilter(after, before *Date) Filter {
+	return Filter{Name: "publishedRange", Keep: func(b Book) bool {
+		b.normalizePublished()
+		if b.Published == nil {
+			return false
+		}
+		if after != nil && b.Published.End().Before(after.Start()) {
+			return false
+		}
+		if before != nil && b.Published.Start().After(before.End()) {
+			return false
+		}
+		return true
+	}}
+}
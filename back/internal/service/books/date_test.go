@@ -0,0 +1,88 @@
+package books
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{"2001", Date{Year: 2001, Precision: PrecisionYear}, false},
+		{"2001-01", Date{Year: 2001, Month: 1, Precision: PrecisionMonth}, false},
+		{"2001-01-10", Date{Year: 2001, Month: 1, Day: 10, Precision: PrecisionDay}, false},
+		{"2001-01-10T00:00:00Z", Date{Year: 2001, Month: 1, Day: 10, Precision: PrecisionDay}, false},
+		{"2001*", Date{Year: 2001, Precision: PrecisionYear}, false},
+		{" 2024-02-29 ", Date{Year: 2024, Month: 2, Day: 29, Precision: PrecisionDay}, false},
+		{"2023-02-29", Date{}, true},
+		{"2001-13", Date{}, true},
+		{"2001-00", Date{}, true},
+		{"2001-01-00", Date{}, true},
+		{"2001-01-10-01", Date{}, true},
+		{"01-10", Date{}, true},
+		{"20011", Date{}, true},
+		{"2001-ab", Date{}, true},
+		{"", Date{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDate(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDate(%q) = %+v, %v; want %+v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("ParseDate(%q) error = %v, want ErrInvalidDate", tt.in, err)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		in         string
+		str        string
+		start, end time.Time
+	}{
+		{"2001", "2001", day(2001, 1, 1), day(2001, 12, 31)},
+		{"2024-02", "2024-02", day(2024, 2, 1), day(2024, 2, 29)},
+		{"2001-01-10", "2001-01-10", day(2001, 1, 10), day(2001, 1, 10)},
+	}
+	for _, tt := range tests {
+		d, err := ParseDate(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDate(%q): %v", tt.in, err)
+		}
+		if d.String() != tt.str || !d.Start().Equal(tt.start) || !d.End().Equal(tt.end) {
+			t.Errorf("%q: String/Start/End = %s %s %s, want %s %s %s",
+				tt.in, d, d.Start().Format(time.DateOnly), d.End().Format(time.DateOnly),
+				tt.str, tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestPublishedRangeFilterWithPartialDates(t *testing.T) {
+	after, _ := ParseDate("2020-06")
+	before, _ := ParseDate("2021")
+	keep := publishedRangeFilter(&after, &before).Keep
+
+	tests := []struct {
+		published string
+		want      bool
+	}{
+		{"2020", true}, // 年だけの日付は期間が重なれば残す
+		{"2020-05", false},
+		{"2020-06-01", true},
+		{"2021-12-31", true},
+		{"2022-01", false},
+		{"", false},
+		{"unknown", false},
+	}
+	for _, tt := range tests {
+		if got := keep(Book{PublishedDate: tt.published}); got != tt.want {
+			t.Errorf("keep(%q) = %v, want %v", tt.published, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package books
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// タイトルの類似度がこれ以上なら同じ作品とみなす
+const editionTitleSimilarity = 0.85
+
+// 版・形態を表す語句（タイトル比較の前に取り除く）
+var editionMarkers = regexp.MustCompile(`(?i)(\(.*?\)|\[.*?\]|（.*?）|\d+(st|nd|rd|th)\s+edition|second edition|third edition|revised edition|\bedition\b|第\s*\d+\s*版|改訂(新)?版|新版|電子書籍版|kindle edition|ebook)`)
+
+// 同じ作品の別版をまとめ、各グループで最も情報の多い1冊を代表として残す。
+// 代表はグループ内で最初に現れた位置に置き、他の版の ID を AlternateEditionIDs に入れる
+func groupEditions(items []Book) ([]Book, int) {
+	type group struct {
+		members []Book
+		isbns   map[string]bool
+		title   []string
+		authors []string
+	}
+	var groups []*group
+	for _, b := range items {
+		title := titleTokens(b.Title)
+		authors := authorKeys(b.Authors)
+
+		var match *group
+		for _, g := range groups {
+			if b.ISBN13 != "" && g.isbns[b.ISBN13] {
+				match = g
+				break
+			}
+			if len(title) > 0 && jaccard(title, g.title) >= editionTitleSimilarity && sameAuthors(authors, g.authors) {
+				match = g
+				break
+			}
+		}
+		if match == nil {
+			match = &group{isbns: make(map[string]bool), title: title, authors: authors}
+			groups = append(groups, match)
+		}
+		match.members = append(match.members, b)
+		if b.ISBN13 != "" {
+			match.isbns[b.ISBN13] = true
+		}
+	}
+
+	out := make([]Book, 0, len(groups))
+	merged := 0
+	for _, g := range groups {
+		best := 0
+		for i, m := range g.members {
+			if completeness(m) > completeness(g.members[best]) {
+				best = i
+			}
+		}
+		rep := g.members[best]
+		for i, m := range g.members {
+			if i != best {
+				rep.AlternateEditionIDs = append(rep.AlternateEditionIDs, m.ID)
+			}
+		}
+		merged += len(g.members) - 1
+		out = append(out, rep)
+	}
+	return out, merged
+}
+
+// 代表を選ぶための情報量スコア
+func completeness(b Book) int {
+	score := 0
+	for _, ok := range []bool{
+		b.Thumbnail != "", b.Description != "", b.PageCount > 0, b.ISBN13 != "",
+		b.Publisher != "", len(b.Categories) > 0, b.PreviewLink != "",
+	} {
+		if ok {
+			score++
+		}
+	}
+	return score
+}
+
+// 比較用にタイトルを語の集合へ正規化する（版表記・括弧書き・記号を除く）
+func titleTokens(title string) []string {
+	t := strings.ToLower(title)
+	t = editionMarkers.ReplaceAllString(t, " ")
+	fields := strings.FieldsFunc(t, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	seen := make(map[string]bool, len(fields))
+	var out []string
+	for _, f := range fields {
+		if !seen[f] {
+			seen[f] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// 著者名を比較用のキー（小文字・記号なしの姓）にする
+func authorKeys(authors []string) []string {
+	var out []string
+	for _, a := range authors {
+		fields := strings.FieldsFunc(strings.ToLower(a), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if len(fields) > 0 {
+			out = append(out, fields[len(fields)-1])
+		}
+	}
+	return out
+}
+
+// 著者が不明同士、または1人でも共通していれば同じとみなす
+func sameAuthors(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, x := range a {
+		set[x] = true
+	}
+	inter := 0
+	for _, y := range b {
+		if set[y] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
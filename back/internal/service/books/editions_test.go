@@ -0,0 +1,91 @@
+package books
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupEditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []Book
+		wantIDs    []string
+		wantAlts   [][]string
+		wantMerged int
+	}{
+		{
+			name: "same ISBN",
+			items: []Book{
+				{ID: "a", Title: "Go言語", ISBN13: "9784873115658"},
+				{ID: "b", Title: "プログラミング言語Go", ISBN13: "9784873115658"},
+			},
+			wantIDs:    []string{"a"},
+			wantAlts:   [][]string{{"b"}},
+			wantMerged: 1,
+		},
+		{
+			name: "edition markers and same author",
+			items: []Book{
+				{ID: "a", Title: "Learning Go", Authors: []string{"Jon Bodner"}},
+				{ID: "b", Title: "Learning Go, 2nd Edition", Authors: []string{"Jon Bodner"}},
+				{ID: "c", Title: "Learning Go (Kindle Edition)", Authors: []string{"J. Bodner"}},
+			},
+			wantIDs:    []string{"a"},
+			wantAlts:   [][]string{{"b", "c"}},
+			wantMerged: 2,
+		},
+		{
+			name: "japanese edition markers",
+			items: []Book{
+				{ID: "a", Title: "リーダブルコード", Authors: []string{"Dustin Boswell"}},
+				{ID: "b", Title: "リーダブルコード 改訂新版", Authors: []string{"Dustin Boswell"}},
+			},
+			wantIDs:    []string{"a"},
+			wantAlts:   [][]string{{"b"}},
+			wantMerged: 1,
+		},
+		{
+			name: "same title by different authors",
+			items: []Book{
+				{ID: "a", Title: "Effective Go", Authors: []string{"Alice Smith"}},
+				{ID: "b", Title: "Effective Go", Authors: []string{"Bob Jones"}},
+			},
+			wantIDs:  []string{"a", "b"},
+			wantAlts: [][]string{nil, nil},
+		},
+		{
+			name: "different titles",
+			items: []Book{
+				{ID: "a", Title: "Go in Action", Authors: []string{"William Kennedy"}},
+				{ID: "b", Title: "Go Programming Blueprints", Authors: []string{"William Kennedy"}},
+			},
+			wantIDs:  []string{"a", "b"},
+			wantAlts: [][]string{nil, nil},
+		},
+		{
+			name: "most complete member represents the group at the first position",
+			items: []Book{
+				{ID: "a", Title: "Concurrency in Go", Authors: []string{"Katherine Cox-Buday"}},
+				{ID: "x", Title: "Other Book"},
+				{ID: "b", Title: "Concurrency in Go", Authors: []string{"Katherine Cox-Buday"}, Description: "d", PageCount: 238, ISBN13: "9781491941195"},
+			},
+			wantIDs:    []string{"b", "x"},
+			wantAlts:   [][]string{{"a"}, nil},
+			wantMerged: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, merged := groupEditions(tt.items)
+			var ids []string
+			var alts [][]string
+			for _, b := range got {
+				ids = append(ids, b.ID)
+				alts = append(alts, b.AlternateEditionIDs)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || !reflect.DeepEqual(alts, tt.wantAlts) || merged != tt.wantMerged {
+				t.Errorf("groupEditions = %v %v merged %d, want %v %v merged %d", ids, alts, merged, tt.wantIDs, tt.wantAlts, tt.wantMerged)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package books
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// ISBN を持つ書籍を MetadataProvider の情報で補完する。
+// 空のフィールドだけを埋め、提供元のエラーは検索全体を失敗させない
+func (s *Service) enrich(ctx context.Context, items []Book) []Book {
+	if s.metadata == nil {
+		return items
+	}
+	var isbns []string
+	seen := make(map[string]bool)
+	for _, b := range items {
+		if b.ISBN13 != "" && !seen[b.ISBN13] && needsEnrichment(b) {
+			seen[b.ISBN13] = true
+			isbns = append(isbns, b.ISBN13)
+		}
+	}
+	if len(isbns) == 0 {
+		return items
+	}
+	found, err := s.metadata.LookupISBNs(ctx, isbns)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			log.Printf("%s: metadata lookup for %d ISBNs failed: %v", s.metadataName, len(isbns), err)
+		}
+		return items
+	}
+	if len(found) == 0 {
+		return items
+	}
+
+	out := make([]Book, len(items))
+	for i, b := range items {
+		if extra, ok := found[b.ISBN13]; ok {
+			b = fillMissing(b, extra, s.metadataName)
+		}
+		out[i] = b
+	}
+	return out
+}
+
+// 補完の余地がある書籍か
+func needsEnrichment(b Book) bool {
+	return b.Thumbnail == "" || b.Description == "" || b.Publisher == "" ||
+		b.PublishedDate == "" || b.PageCount == 0 || len(b.Authors) == 0
+}
+
+// b の空フィールドを extra の値で埋め、埋めたフィールドの提供元を Sources に記録する
+func fillMissing(b, extra Book, source string) Book {
+	// Sources はキャッシュ上の書籍と共有している可能性があるため、書き込む前に複製する
+	copied := false
+	fill := func(field string, empty bool, set func()) {
+		if !empty {
+			return
+		}
+		set()
+		if !copied {
+			sources := make(map[string]string, len(b.Sources)+1)
+			for k, v := range b.Sources {
+				sources[k] = v
+			}
+			b.Sources = sources
+			copied = true
+		}
+		b.Sources[field] = source
+	}
+	fill("Title", b.Title == "" && extra.Title != "", func() { b.Title = extra.Title })
+	fill("Subtitle", b.Subtitle == "" && extra.Subtitle != "", func() { b.Subtitle = extra.Subtitle })
+	fill("Authors", len(b.Authors) == 0 && len(extra.Authors) > 0, func() { b.Authors = extra.Authors })
+	fill("Publisher", b.Publisher == "" && extra.Publisher != "", func() { b.Publisher = extra.Publisher })
+	fill("PublishedDate", b.PublishedDate == "" && extra.PublishedDate != "", func() {
+		b.PublishedDate, b.Published = extra.PublishedDate, extra.Published
+	})
+	fill("Description", b.Description == "" && extra.Description != "", func() { b.Description = extra.Description })
+	fill("Categories", len(b.Categories) == 0 && len(extra.Categories) > 0, func() { b.Categories = extra.Categories })
+	fill("PageCount", b.PageCount == 0 && extra.PageCount > 0, func() { b.PageCount = extra.PageCount })
+	fill("Thumbnail", b.Thumbnail == "" && extra.Thumbnail != "", func() { b.Thumbnail = extra.Thumbnail })
+	fill("Language", b.Language == "" && extra.Language != "", func() { b.Language = extra.Language })
+	fill("ISBN13", b.ISBN13 == "" && extra.ISBN13 != "", func() { b.ISBN13, b.ISBN10 = extra.ISBN13, extra.ISBN10 })
+	fill("AverageRating", b.AverageRating == 0 && extra.AverageRating > 0, func() {
+		b.AverageRating, b.RatingsCount = extra.AverageRating, extra.RatingsCount
+	})
+	return b
+}
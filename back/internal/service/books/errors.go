@@ -0,0 +1,61 @@
+package books
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 該当する書籍が存在しない
+var ErrNotFound = errors.New("book not found")
+
+// 上流（外部書籍 API）由来のエラー種別
+var (
+	// レート制限・クォータ超過
+	ErrRateLimited = errors.New("upstream rate limited")
+	// 上流の応答がタイムアウトした
+	ErrUpstreamTimeout = errors.New("upstream timeout")
+	// API キーなど認証情報が拒否された
+	ErrUpstreamUnauthorized = errors.New("upstream rejected credentials")
+	// 上流のレスポンスを解釈できなかった
+	ErrBadUpstreamPayload = errors.New("bad upstream payload")
+	// 呼び出し元がリクエストをキャンセルした
+	ErrCanceled = errors.New("request canceled")
+	// 上流が一時的に利用できない（5xx やサーキットブレーカー open）
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// 上記以外の上流エラー
+	ErrUpstream = errors.New("upstream error")
+	// 再生専用モードで録画の無いリクエスト（再試行も保存済み応答での代用もしない）
+	ErrUpstreamNotRecorded = errors.New("upstream request not recorded")
+)
+
+// 上流エラーの詳細。errors.Is で Kind（上記のいずれか）と原因の両方に一致する
+type UpstreamError struct {
+	Kind       error
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// エラーに付随する再試行までの待ち時間（不明なら 0）
+func RetryAfter(err error) time.Duration {
+	var ue *UpstreamError
+	if errors.As(err, &ue) {
+		return ue.RetryAfter
+	}
+	return 0
+}
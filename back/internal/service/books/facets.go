@@ -0,0 +1,147 @@
+package books
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 1つのファセットあたりに返すバケット数の上限
+const maxFacetBuckets = 20
+
+// ファセットの1バケット
+type FacetBucket struct {
+	Value string
+	Count int
+}
+
+// 検索結果の絞り込み用ファセット
+type Facets struct {
+	// 集計に使った書籍数
+	Window     int
+	Categories []FacetBucket
+	Languages  []FacetBucket
+	Decades    []FacetBucket
+	Publishers []FacetBucket
+	PageCounts []FacetBucket
+}
+
+// ページ数の区分
+var pageCountBands = []struct {
+	label string
+	max   int // この値未満
+}{
+	{"<100", 100},
+	{"100-299", 300},
+	{"300-499", 500},
+	{"500+", 1 << 31},
+}
+
+// 書籍の集合からファセットを集計する
+func computeFacets(items []Book) *Facets {
+	categories := map[string]int{}
+	languages := map[string]int{}
+	decades := map[string]int{}
+	publishers := map[string]int{}
+	pages := map[string]int{}
+
+	for _, b := range items {
+		seen := map[string]bool{}
+		for _, c := range b.Categories {
+			if c = strings.TrimSpace(c); c != "" && !seen[c] {
+				seen[c] = true
+				categories[c]++
+			}
+		}
+		if b.Language != "" {
+			languages[b.Language]++
+		}
+		b.normalizePublished()
+		if b.Published != nil {
+			decades[fmt.Sprintf("%ds", b.Published.Year/10*10)]++
+		}
+		if p := strings.TrimSpace(b.Publisher); p != "" {
+			publishers[p]++
+		}
+		if b.PageCount > 0 {
+			for _, band := range pageCountBands {
+				if b.PageCount < band.max {
+					pages[band.label]++
+					break
+				}
+			}
+		}
+	}
+
+	return &Facets{
+		Window:     len(items),
+		Categories: buckets(categories, byCount),
+		Languages:  buckets(languages, byCount),
+		Decades:    buckets(decades, byValueDesc),
+		Publishers: buckets(publishers, byCount),
+		PageCounts: buckets(pages, byBand),
+	}
+}
+
+type bucketOrder int
+
+const (
+	byCount bucketOrder = iota
+	byValueDesc
+	byBand
+)
+
+func buckets(counts map[string]int, order bucketOrder) []FacetBucket {
+	out := make([]FacetBucket, 0, len(counts))
+	for v, c := range counts {
+		out = append(out, FacetBucket{Value: v, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		switch order {
+		case byValueDesc:
+			return out[i].Value > out[j].Value
+		case byBand:
+			return bandIndex(out[i].Value) < bandIndex(out[j].Value)
+		default:
+			if out[i].Count != out[j].Count {
+				return out[i].Count > out[j].Count
+			}
+			return out[i].Value < out[j].Value
+		}
+	})
+	if len(out) > maxFacetBuckets {
+		out = out[:maxFacetBuckets]
+	}
+	return out
+}
+
+func bandIndex(label string) int {
+	for i, band := range pageCountBands {
+		if band.label == label {
+			return i
+		}
+	}
+	return len(pageCountBands)
+}
+
+// 表示中のページに加え、facetWindow までの後続結果も取得してファセットを集計する。
+// 追加の取得に失敗した場合は表示中のページだけで集計する
+func (s *Service) facetsFor(ctx context.Context, params SearchParams, w window, shown []Book) *Facets {
+	sample := append([]Book(nil), shown...)
+	extra := min(params.FacetWindow, s.paging.MaxFacetWindow) - len(w.items)
+	if extra > 0 && !w.exhausted {
+		p := params
+		p.StartIndex = w.next
+		p.LangOffsets = w.langOffsets
+		p.LangEnds = w.langEnds
+		if more, err := s.window(ctx, p, extra); err == nil {
+			items, _ := applyFilters(more.items, s.filtersFor(params))
+			if !params.KeepEditions {
+				items, _ = groupEditions(items)
+			}
+			sample = append(sample, items...)
+		}
+	}
+	return computeFacets(sample)
+}
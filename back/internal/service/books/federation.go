@@ -0,0 +1,269 @@
+package books
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 横断検索に参加する書籍検索プロバイダ
+type Provider struct {
+	// Book.Provider や Sources に記録する名前
+	Name   string
+	Client ExternalClient
+	// プロバイダごとの待ち時間の上限（0 なら呼び出し元のコンテキストに従う）
+	Timeout time.Duration
+	// このプロバイダが発行する ID の接頭辞（例: "ol:"）。空なら接頭辞の無い ID を担当する
+	IDPrefix string
+}
+
+// 複数のプロバイダを並列に検索し、結果をまとめる ExternalClient。
+// 全体の並びは先頭（主）のプロバイダの結果の後に、次のプロバイダの結果を先頭から続けたものとする。
+// 主の結果で埋まらなかった枠だけを次のプロバイダが埋め、同じ書籍（ISBN またはタイトル＋著者）は
+// 先に並んだ書籍に不足分を補う
+type Federation struct {
+	providers []Provider
+}
+
+func NewFederation(providers ...Provider) *Federation {
+	return &Federation{providers: providers}
+}
+
+type providerResult struct {
+	res SearchResult
+	err error
+}
+
+// 全体の [StartIndex, StartIndex+MaxResults) に当たる書籍を返す。
+// 全プロバイダに同じ条件で並列に問い合わせ、前のプロバイダが尽きた後の枠は
+// そのプロバイダの先頭側（全体の位置 - 前のプロバイダの件数）から取り直す。
+// 重複としてまとめた書籍も位置を1つ使うため、読み進めた位置は NextStartIndex で返す。
+// TotalItems は各プロバイダの件数の和。一部のプロバイダが失敗しても残りの結果を返し、失敗は ProviderErrors に記録する
+func (f *Federation) Search(ctx context.Context, params SearchParams) (SearchResult, error) {
+	results := make([]providerResult, len(f.providers))
+	var wg sync.WaitGroup
+	for i, p := range f.providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results[i].res, results[i].err = f.searchOne(ctx, p, params)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var (
+		merged   SearchResult
+		firstErr error
+		fetched  int
+	)
+	fail := func(name string, err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		if merged.ProviderErrors == nil {
+			merged.ProviderErrors = make(map[string]string)
+		}
+		merged.ProviderErrors[name] = err.Error()
+	}
+
+	pg := newFederatedPage(params.StartIndex, params.MaxResults)
+	// 現在のプロバイダの先頭に当たる全体の位置
+	begin := 0
+	for i, p := range f.providers {
+		r := results[i]
+		if r.err != nil {
+			fail(p.Name, r.err)
+			continue
+		}
+		res, offset, requested := r.res, params.StartIndex, params.MaxResults
+		if from := pg.pos - begin; !pg.full() && params.MaxResults > 0 && from != offset {
+			// 同じ順位の結果は既に並んだ書籍の補完にだけ使い、空いた枠はこのプロバイダの from 件目から埋める
+			pg.enrich(r.res.Items, p.Name)
+			q := params
+			q.StartIndex, q.MaxResults = from, pg.remaining()
+			backfill, err := f.searchOne(ctx, p, q)
+			if err != nil {
+				fail(p.Name, err)
+				continue
+			}
+			res, offset, requested = backfill, from, q.MaxResults
+		}
+		fetched++
+		if res.Stale {
+			merged.Stale = true
+			merged.StaleAgeSeconds = max(merged.StaleAgeSeconds, res.StaleAgeSeconds)
+		}
+		pg.take(res.Items, p.Name)
+		begin += providerLength(res, offset, requested)
+	}
+	if fetched == 0 {
+		return SearchResult{}, firstErr
+	}
+	merged.Items = pg.items
+	merged.NextStartIndex = pg.pos
+	merged.TotalItems = max(begin, pg.pos)
+	return merged, nil
+}
+
+func (f *Federation) searchOne(ctx context.Context, p Provider, params SearchParams) (SearchResult, error) {
+	pctx, cancel := withTimeout(ctx, p.Timeout)
+	defer cancel()
+	return p.Client.Search(pctx, params)
+}
+
+// プロバイダの結果の件数。offset から requested 件を求めて足りなければそこが終端。
+// 結果が空の場合は終端の位置が分からないため、totalItems が offset 以下ならそれを、超えていれば offset を使う
+func providerLength(res SearchResult, offset, requested int) int {
+	n := len(res.Items)
+	switch {
+	case n >= requested || requested <= 0:
+		return max(res.TotalItems, offset+n)
+	case n > 0 || offset == 0:
+		return offset + n
+	default:
+		return min(res.TotalItems, offset)
+	}
+}
+
+// 横断検索の1ページ分を組み立てる
+type federatedPage struct {
+	start, size int
+	// 次に埋める全体の位置
+	pos   int
+	items []Book
+	index map[string]int
+}
+
+func newFederatedPage(start, size int) *federatedPage {
+	return &federatedPage{start: start, size: size, pos: start, index: make(map[string]int)}
+}
+
+func (pg *federatedPage) full() bool {
+	return pg.size > 0 && pg.pos >= pg.start+pg.size
+}
+
+func (pg *federatedPage) remaining() int {
+	return pg.start + pg.size - pg.pos
+}
+
+// 書籍を順に枠へ入れる。既に並んでいる書籍と同じなら不足分を補って位置だけ進める。
+// ページが埋まった後の書籍は補完にだけ使う
+func (pg *federatedPage) take(items []Book, provider string) {
+	for i, b := range items {
+		if pg.full() {
+			pg.enrich(items[i:], provider)
+			return
+		}
+		if b.Provider == "" {
+			b.Provider = provider
+		}
+		pg.pos++
+		if !pg.merge(b) {
+			for _, k := range mergeKeys(b) {
+				pg.index[k] = len(pg.items)
+			}
+			pg.items = append(pg.items, b)
+		}
+	}
+}
+
+// 既に並んでいる書籍と同じものだけを使い、不足しているフィールドを補う
+func (pg *federatedPage) enrich(items []Book, provider string) {
+	for _, b := range items {
+		if b.Provider == "" {
+			b.Provider = provider
+		}
+		pg.merge(b)
+	}
+}
+
+func (pg *federatedPage) merge(b Book) bool {
+	pos, ok := lookupAny(pg.index, mergeKeys(b))
+	if !ok {
+		return false
+	}
+	pg.items[pos] = fillMissing(pg.items[pos], b, b.Provider)
+	for _, k := range mergeKeys(pg.items[pos]) {
+		pg.index[k] = pos
+	}
+	return true
+}
+
+// ID の接頭辞で担当プロバイダを選んで取得する
+func (f *Federation) Get(ctx context.Context, id string) (Book, error) {
+	for _, p := range f.providers {
+		if !f.owns(p, id) {
+			continue
+		}
+		pctx, cancel := withTimeout(ctx, p.Timeout)
+		b, err := p.Client.Get(pctx, id)
+		cancel()
+		if err != nil {
+			return Book{}, err
+		}
+		if b.Provider == "" {
+			b.Provider = p.Name
+		}
+		return b, nil
+	}
+	return Book{}, ErrNotFound
+}
+
+// プロバイダ名の一覧
+func (f *Federation) Providers() []string {
+	names := make([]string, len(f.providers))
+	for i, p := range f.providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func (f *Federation) owns(p Provider, id string) bool {
+	if p.IDPrefix != "" {
+		return strings.HasPrefix(id, p.IDPrefix)
+	}
+	for _, other := range f.providers {
+		if other.IDPrefix != "" && strings.HasPrefix(id, other.IDPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// 同一書籍の判定に使うキー（ID、ISBN とタイトル＋筆頭著者）
+func mergeKeys(b Book) []string {
+	var keys []string
+	if b.ID != "" {
+		keys = append(keys, "id:"+b.ID)
+	}
+	if b.ISBN13 != "" {
+		keys = append(keys, "isbn:"+b.ISBN13)
+	}
+	if title := titleTokens(b.Title); len(title) > 0 {
+		author := ""
+		if a := authorKeys(b.Authors); len(a) > 0 {
+			author = a[0]
+		}
+		keys = append(keys, "title:"+strings.Join(title, " ")+"/"+author)
+	}
+	return keys
+}
+
+func lookupAny(index map[string]int, keys []string) (int, bool) {
+	for _, k := range keys {
+		if pos, ok := index[k]; ok {
+			return pos, true
+		}
+	}
+	return 0, false
+}
+
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+var _ ExternalClient = (*Federation)(nil)
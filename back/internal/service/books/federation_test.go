@@ -0,0 +1,167 @@
+package books
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// 先頭から n 件の書籍を持つ上流のスタブ（totalItems は total を報告する）
+type stubClient struct {
+	prefix string
+	n      int
+	total  int
+	// 先頭の shared 件は primary と同じ ISBN を持つ
+	shared int
+}
+
+func (c stubClient) Search(ctx context.Context, p SearchParams) (SearchResult, error) {
+	res := SearchResult{TotalItems: c.total}
+	for i := p.StartIndex; i < c.n && i < p.StartIndex+p.MaxResults; i++ {
+		isbn := fmt.Sprintf("%s-%d", c.prefix, i)
+		if i < c.shared {
+			isbn = fmt.Sprintf("g-%d", i)
+		}
+		res.Items = append(res.Items, Book{
+			ID:     fmt.Sprintf("%s%d", c.prefix, i),
+			Title:  fmt.Sprintf("%s title %d", c.prefix, i),
+			ISBN13: isbn,
+		})
+	}
+	return res, nil
+}
+
+func (c stubClient) Get(ctx context.Context, id string) (Book, error) {
+	return Book{}, ErrNotFound
+}
+
+func TestFederationKeepsPageSizeAndTotals(t *testing.T) {
+	tests := []struct {
+		name        string
+		google      stubClient
+		openLibrary stubClient
+		wantItems   int
+		wantTotal   int
+		wantHasMore bool
+		// 先頭ページに並ぶ主の書籍数
+		wantGoogle int
+	}{
+		{
+			name:        "few primary hits",
+			google:      stubClient{prefix: "g", n: 5, total: 5},
+			openLibrary: stubClient{prefix: "ol:", n: 500, total: 500},
+			wantItems:   10,
+			wantTotal:   505,
+			wantHasMore: true,
+			wantGoogle:  5,
+		},
+		{
+			name:        "full primary page",
+			google:      stubClient{prefix: "g", n: 30, total: 30},
+			openLibrary: stubClient{prefix: "ol:", n: 500, total: 500},
+			wantItems:   10,
+			wantTotal:   530,
+			wantHasMore: true,
+			wantGoogle:  10,
+		},
+		{
+			// まとめた書籍も位置を1つ使うため、総件数は読み進めた位置になる
+			name:        "shared books are merged",
+			google:      stubClient{prefix: "g", n: 4, total: 4},
+			openLibrary: stubClient{prefix: "ol:", n: 4, total: 4, shared: 2},
+			wantItems:   6,
+			wantTotal:   8,
+			wantHasMore: false,
+			wantGoogle:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fed := NewFederation(
+				Provider{Name: "googlebooks", Client: tt.google},
+				Provider{Name: "openlibrary", Client: tt.openLibrary, IDPrefix: "ol:"},
+			)
+			res, err := NewService(fed).Search(context.Background(), SearchParams{Query: "go", MaxResults: 10, KeepEditions: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Items) != tt.wantItems {
+				t.Errorf("items = %d, want %d", len(res.Items), tt.wantItems)
+			}
+			if res.TotalItems != tt.wantTotal {
+				t.Errorf("TotalItems = %d, want %d", res.TotalItems, tt.wantTotal)
+			}
+			if res.HasMore != tt.wantHasMore {
+				t.Errorf("HasMore = %v, want %v", res.HasMore, tt.wantHasMore)
+			}
+			google := 0
+			for _, b := range res.Items {
+				if b.Provider == "googlebooks" {
+					google++
+				}
+			}
+			if google != tt.wantGoogle {
+				t.Errorf("googlebooks items = %d, want %d", google, tt.wantGoogle)
+			}
+		})
+	}
+}
+
+func TestFederationPagesPastThePrimary(t *testing.T) {
+	fed := NewFederation(
+		Provider{Name: "googlebooks", Client: stubClient{prefix: "g", n: 15, total: 15}},
+		Provider{Name: "openlibrary", Client: stubClient{prefix: "ol:", n: 12, total: 12}, IDPrefix: "ol:"},
+	)
+	s := NewService(fed)
+	wantPages := [][]string{
+		{"g0", "g1", "g2", "g3", "g4", "g5", "g6", "g7", "g8", "g9"},
+		{"g10", "g11", "g12", "g13", "g14", "ol:0", "ol:1", "ol:2", "ol:3", "ol:4"},
+		{"ol:5", "ol:6", "ol:7", "ol:8", "ol:9", "ol:10", "ol:11"},
+	}
+	start := 0
+	for page, want := range wantPages {
+		res, err := s.Search(context.Background(), SearchParams{Query: "go", StartIndex: start, MaxResults: 10, KeepEditions: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, b := range res.Items {
+			ids = append(ids, b.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Fatalf("page %d = %v, want %v", page, ids, want)
+		}
+		last := page == len(wantPages)-1
+		if res.TotalItems != 27 || res.HasMore == last {
+			t.Fatalf("page %d: total %d, hasMore %v", page, res.TotalItems, res.HasMore)
+		}
+		start = res.NextStartIndex
+	}
+}
+
+func TestFederationUsesSecondaryWhenPrimaryFails(t *testing.T) {
+	fed := NewFederation(
+		Provider{Name: "googlebooks", Client: failingClient{}},
+		Provider{Name: "openlibrary", Client: stubClient{prefix: "ol:", n: 30, total: 30}, IDPrefix: "ol:"},
+	)
+	res, err := NewService(fed).Search(context.Background(), SearchParams{Query: "go", StartIndex: 10, MaxResults: 10, KeepEditions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Items) != 10 || res.Items[0].ID != "ol:10" || !res.HasMore {
+		t.Fatalf("items %d (first %v), hasMore %v", len(res.Items), res.Items, res.HasMore)
+	}
+	if _, ok := res.ProviderErrors["googlebooks"]; !ok {
+		t.Errorf("ProviderErrors = %v, want googlebooks", res.ProviderErrors)
+	}
+}
+
+type failingClient struct{}
+
+func (failingClient) Search(ctx context.Context, p SearchParams) (SearchResult, error) {
+	return SearchResult{}, ErrUpstreamUnavailable
+}
+
+func (failingClient) Get(ctx context.Context, id string) (Book, error) {
+	return Book{}, ErrUpstreamUnavailable
+}
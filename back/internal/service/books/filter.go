@@ -0,0 +1,134 @@
+package books
+
+import (
+	"strings"
+)
+
+// 検索結果の1件を残すか判定するフィルタ
+type Filter struct {
+	Name string
+	Keep func(Book) bool
+}
+
+// 組み込みフィルタの設定。サーバーのデフォルトとリクエストごとの指定の両方に使う
+type FilterOptions struct {
+	// true ならフィルタを一切適用しない
+	Disabled bool `json:"Disabled,omitempty"`
+	// いずれかのカテゴリを含む書籍だけを残す（部分一致・大文字小文字を区別しない）
+	AllowCategories []string `json:"AllowCategories,omitempty"`
+	// いずれかのカテゴリを含む書籍を除く
+	DenyCategories []string `json:"DenyCategories,omitempty"`
+	// ページ数がこれ未満の書籍を除く（ページ数不明の書籍は残す）。nil は未指定、0 は制限なし
+	MinPageCount *int `json:"MinPageCount,omitempty"`
+	// true なら説明文の無い書籍を除く。nil は未指定
+	RequireDescription *bool `json:"RequireDescription,omitempty"`
+	// タイトル・サブタイトル・説明文・カテゴリにこれらの語を含む書籍を除く
+	ExcludeKeywords []string `json:"ExcludeKeywords,omitempty"`
+}
+
+// base の上に o を重ねる。o で指定された（nil でない）項目が優先されるため、
+// minPages=0 や requireDescription=false でサーバーのデフォルトを緩められる
+func (o FilterOptions) Merge(base FilterOptions) FilterOptions {
+	merged := base
+	merged.Disabled = o.Disabled || base.Disabled
+	if o.AllowCategories != nil {
+		merged.AllowCategories = o.AllowCategories
+	}
+	if o.DenyCategories != nil {
+		merged.DenyCategories = o.DenyCategories
+	}
+	if o.MinPageCount != nil {
+		merged.MinPageCount = o.MinPageCount
+	}
+	if o.RequireDescription != nil {
+		merged.RequireDescription = o.RequireDescription
+	}
+	if o.ExcludeKeywords != nil {
+		merged.ExcludeKeywords = o.ExcludeKeywords
+	}
+	return merged
+}
+
+// 設定から組み込みフィルタの列を作る
+func (o FilterOptions) Filters() []Filter {
+	if o.Disabled {
+		return nil
+	}
+	var filters []Filter
+	if allow := lowerAll(o.AllowCategories); len(allow) > 0 {
+		filters = append(filters, Filter{Name: "allowCategories", Keep: func(b Book) bool {
+			return matchesAny(b.Categories, allow)
+		}})
+	}
+	if deny := lowerAll(o.DenyCategories); len(deny) > 0 {
+		filters = append(filters, Filter{Name: "denyCategories", Keep: func(b Book) bool {
+			return !matchesAny(b.Categories, deny)
+		}})
+	}
+	if o.MinPageCount != nil && *o.MinPageCount > 0 {
+		min := *o.MinPageCount
+		filters = append(filters, Filter{Name: "minPageCount", Keep: func(b Book) bool {
+			return b.PageCount == 0 || b.PageCount >= min
+		}})
+	}
+	if o.RequireDescription != nil && *o.RequireDescription {
+		filters = append(filters, Filter{Name: "requireDescription", Keep: func(b Book) bool {
+			return strings.TrimSpace(b.Description) != ""
+		}})
+	}
+	if words := lowerAll(o.ExcludeKeywords); len(words) > 0 {
+		filters = append(filters, Filter{Name: "excludeKeywords", Keep: func(b Book) bool {
+			fields := append([]string{b.Title, b.Subtitle, b.Description}, b.Categories...)
+			return !matchesAny(fields, words)
+		}})
+	}
+	return filters
+}
+
+// フィルタを順に適用し、残った書籍とフィルタごとの除外件数を返す
+func applyFilters(items []Book, filters []Filter) ([]Book, map[string]int) {
+	if len(filters) == 0 {
+		return items, nil
+	}
+	kept := make([]Book, 0, len(items))
+	dropped := make(map[string]int)
+	for _, b := range items {
+		keep := true
+		for _, f := range filters {
+			if !f.Keep(b) {
+				dropped[f.Name]++
+				keep = false
+				break
+			}
+		}
+		if keep {
+			kept = append(kept, b)
+		}
+	}
+	if len(dropped) == 0 {
+		dropped = nil
+	}
+	return kept, dropped
+}
+
+func lowerAll(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func matchesAny(fields, needles []string) bool {
+	for _, f := range fields {
+		f = strings.ToLower(f)
+		for _, n := range needles {
+			if strings.Contains(f, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package books
+
+import "testing"
+
+func TestFilterOptionsMerge(t *testing.T) {
+	intp := func(n int) *int { return &n }
+	boolp := func(b bool) *bool { return &b }
+	defaults := FilterOptions{MinPageCount: intp(100), RequireDescription: boolp(true)}
+	books := []Book{
+		{ID: "short", PageCount: 50, Description: "x"},
+		{ID: "nodesc", PageCount: 300},
+		{ID: "ok", PageCount: 300, Description: "x"},
+	}
+	tests := []struct {
+		name    string
+		request FilterOptions
+		want    []string
+	}{
+		{"defaults", FilterOptions{}, []string{"ok"}},
+		{"minPages=0 lifts the default", FilterOptions{MinPageCount: intp(0)}, []string{"short", "ok"}},
+		{"requireDescription=false lifts the default", FilterOptions{RequireDescription: boolp(false)}, []string{"nodesc", "ok"}},
+		{"both lifted", FilterOptions{MinPageCount: intp(0), RequireDescription: boolp(false)}, []string{"short", "nodesc", "ok"}},
+		{"tightened", FilterOptions{MinPageCount: intp(400)}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, _ := applyFilters(books, tt.request.Merge(defaults).Filters())
+			got := make([]string, 0, len(kept))
+			for _, b := range kept {
+				got = append(got, b.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("kept %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("kept %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
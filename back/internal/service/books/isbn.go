@@ -0,0 +1,98 @@
+package books
+
+import (
+	"errors"
+	"strings"
+)
+
+// ISBN として解釈できない入力
+var ErrInvalidISBN = errors.New("invalid isbn")
+
+// ISBN-10 / ISBN-13 の文字列を検証し、ISBN-13 に正規化して返す。
+// ハイフン・空白は無視し、ISBN-10 のチェックデジット "x" は大文字として扱う。
+func NormalizeISBN(raw string) (string, error) {
+	s := compactISBN(raw)
+	switch {
+	case ValidISBN13(s):
+		return s, nil
+	case ValidISBN10(s):
+		return ISBN10To13(s)
+	default:
+		return "", ErrInvalidISBN
+	}
+}
+
+// ISBN-10 のチェックデジットを検証する
+func ValidISBN10(s string) bool {
+	if len(s) != 10 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var d int
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c == 'X' && i == 9:
+			d = 10
+		default:
+			return false
+		}
+		sum += d * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// ISBN-13 のチェックデジットを検証する（978/979 始まりのみ）
+func ValidISBN13(s string) bool {
+	if len(s) != 13 || !(strings.HasPrefix(s, "978") || strings.HasPrefix(s, "979")) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// ISBN-10 を ISBN-13（978 プレフィックス）に変換する
+func ISBN10To13(s string) (string, error) {
+	s = compactISBN(s)
+	if !ValidISBN10(s) {
+		return "", ErrInvalidISBN
+	}
+	body := "978" + s[:9]
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	check := (10 - sum%10) % 10
+	return body + string(rune('0'+check)), nil
+}
+
+func compactISBN(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r == '-' || r == ' ':
+			continue
+		case r == 'x':
+			b.WriteRune('X')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package books
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidISBN10(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"4873115655", true},
+		{"0134190440", true},
+		{"080442957X", true},
+		{"4873115656", false}, // wrong check digit
+		{"08044295X7", false}, // X only as the check digit
+		{"080442957x", false}, // lower case is normalized by callers, not here
+		{"487311565", false},
+		{"48731156555", false},
+		{"48731a5655", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidISBN10(tt.in); got != tt.want {
+			t.Errorf("ValidISBN10(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidISBN13(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"9784873115658", true},
+		{"9780134190440", true},
+		{"9791032305690", true},
+		{"9784873115659", false}, // wrong check digit
+		{"9774873115652", false}, // not a 978/979 prefix
+		{"978487311565", false},
+		{"978487311565X", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidISBN13(tt.in); got != tt.want {
+			t.Errorf("ValidISBN13(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestISBN10To13(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"4873115655", "9784873115658", false},
+		{"4-87311-565-5", "9784873115658", false},
+		{"0134190440", "9780134190440", false},
+		{"080442957X", "9780804429573", false},
+		{"0-8044-2957-x", "9780804429573", false},
+		{"4774142042", "9784774142043", false},
+		{"4873115656", "", true},
+		{"9784873115658", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ISBN10To13(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ISBN10To13(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidISBN) {
+			t.Errorf("ISBN10To13(%q) error = %v, want ErrInvalidISBN", tt.in, err)
+		}
+	}
+}
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"978-4-87311-565-8", "9784873115658", false},
+		{" 978 4873115658 ", "9784873115658", false},
+		{"4-87311-565-5", "9784873115658", false},
+		{"080442957x", "9780804429573", false},
+		{"978-4-87311-565-9", "", true},
+		{"golang", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeISBN(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeISBN(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
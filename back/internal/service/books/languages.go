@@ -0,0 +1,308 @@
+package books
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// 1回の検索で指定できる言語数の上限
+const MaxLanguages = 5
+
+// Langs が複数なら言語ごとに並列に検索して結果を交互に並べ、そうでなければ通常どおり取得する
+func (s *Service) window(ctx context.Context, params SearchParams, size int) (window, error) {
+	if len(params.Langs) < 2 {
+		return s.fetchWindow(ctx, params, size)
+	}
+	return s.fetchLanguages(ctx, params, size)
+}
+
+// 1ページを埋めるために言語ごとの結果を取り直す回数の上限。
+// 位置を求め直す分（言語ごとに最大1回）と、尽きた言語の枠を埋める分を含む
+const maxLanguageRounds = MaxLanguages + 2
+
+// 1ページ分の言語ごとの取得状態
+type languageState struct {
+	lang string
+	// このページで読み始めた、その言語内の位置
+	offset int
+	// offset から取得済みの書籍と、そのうちページに使った件数
+	items []Book
+	used  int
+	total int
+	// その言語の結果の件数（分からなければ -1）
+	end int
+	// 一度でも取得したか
+	fetched bool
+	// 取得に失敗した理由
+	err error
+}
+
+// 次に並べる、その言語内の位置
+func (l *languageState) rank() int {
+	return l.offset + l.used
+}
+
+// もう並べる書籍が無い言語か
+func (l *languageState) done() bool {
+	return l.err != nil || (l.end >= 0 && l.rank() >= l.end)
+}
+
+// 結果を言語ごとに1件ずつ交互に並べた全体の並びから、位置 start 以降の size 件を取り出す。
+// 全体の並びは順位（その言語内の位置）の小さい順、同じ順位なら params.Langs の順で、
+// 結果が尽きた言語は飛ばすため、尽きた言語の枠は残りの言語で埋まる。
+// 各言語の読み始めの位置はカーソルの LangOffsets を使い、無ければ分かっている件数から求める。
+// 次ページの各言語の位置と分かった件数は window.langOffsets / langEnds で返す。
+// 一部の言語だけ失敗した場合は ProviderErrors に "lang:xx" として記録し、残りの結果を返す
+func (s *Service) fetchLanguages(ctx context.Context, params SearchParams, size int) (window, error) {
+	n := len(params.Langs)
+	start := params.StartIndex
+	fromCursor := params.LangOffsets != nil
+
+	langs := make([]*languageState, n)
+	for i, lang := range params.Langs {
+		langs[i] = &languageState{lang: lang, end: -1}
+		if end, ok := params.LangEnds[lang]; ok {
+			langs[i].end = end
+		}
+	}
+	// 各言語の読み始めの位置を決め、取得済みの分を捨てる
+	place := func() {
+		ends := make([]int, n)
+		for i, l := range langs {
+			ends[i] = l.end
+		}
+		offsets := languageOffsets(start, ends)
+		for i, l := range langs {
+			l.offset = offsets[i]
+			if off, ok := params.LangOffsets[l.lang]; ok {
+				l.offset = off
+			}
+			l.items, l.used = nil, 0
+		}
+	}
+	place()
+
+	merged := window{next: start, languageTotals: make(map[string]int, n)}
+	var (
+		items []Book
+		index = make(map[string]int)
+	)
+	for round := 0; round < maxLanguageRounds && len(items) < size; round++ {
+		if !s.fetchLanguageItems(ctx, params, langs, languageCounts(langs, size-len(items)), &merged) {
+			break
+		}
+		if !fromCursor && overshot(langs) {
+			// 件数の分かった言語が求めた位置より短かった。全体の位置 start に当たる位置を求め直す
+			place()
+			items, index = nil, make(map[string]int)
+			continue
+		}
+		items = takeLanguages(langs, items, index, size)
+	}
+
+	var firstErr error
+	ok := 0
+	merged.exhausted = true
+	merged.langOffsets = make(map[string]int, n)
+	for _, l := range langs {
+		merged.langOffsets[l.lang] = l.rank()
+		switch {
+		case l.err != nil:
+			if firstErr == nil {
+				firstErr = l.err
+			}
+		case l.end >= 0:
+			ok++
+			if merged.langEnds == nil {
+				merged.langEnds = make(map[string]int, n)
+			}
+			merged.langEnds[l.lang] = l.end
+			merged.total += l.end
+			merged.languageTotals[l.lang] = l.end
+			merged.exhausted = merged.exhausted && l.done()
+		case l.fetched:
+			ok++
+			merged.total += l.total
+			merged.languageTotals[l.lang] = l.total
+			merged.exhausted = false
+		default:
+			// size が言語数より小さく、このページに枠の無かった言語
+			merged.exhausted = false
+		}
+	}
+	if ok == 0 && firstErr != nil {
+		return window{}, firstErr
+	}
+	merged.items = items
+	merged.next = start + len(items)
+	merged.total = max(merged.total, merged.next)
+	return merged, nil
+}
+
+// 件数の分かった言語に、その件数より後ろの位置を割り当てていたか
+func overshot(langs []*languageState) bool {
+	for _, l := range langs {
+		if l.err == nil && l.end >= 0 && l.end < l.offset {
+			return true
+		}
+	}
+	return false
+}
+
+// 全体の並びで次の remaining 件を各言語に割り当てたときの、各言語の件数
+func languageCounts(langs []*languageState, remaining int) []int {
+	counts := make([]int, len(langs))
+	for ; remaining > 0; remaining-- {
+		best := -1
+		for i, l := range langs {
+			r := l.rank() + counts[i]
+			if l.err != nil || (l.end >= 0 && r >= l.end) {
+				continue
+			}
+			if best < 0 || r < langs[best].rank()+counts[best] {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		counts[best]++
+	}
+	return counts
+}
+
+// 各言語に counts[i] 件の枠があるとして、取得済みの分で足りない言語の続きを並列に取得する。
+// 取得する言語が無ければ false を返す
+func (s *Service) fetchLanguageItems(ctx context.Context, params SearchParams, langs []*languageState, counts []int, merged *window) bool {
+	need := make([]int, len(langs))
+	fetching := false
+	for i, l := range langs {
+		if l.err == nil && l.end < 0 {
+			need[i] = counts[i] - (len(l.items) - l.used)
+			fetching = fetching || need[i] > 0
+		}
+	}
+	if !fetching {
+		return false
+	}
+
+	results := make([]window, len(langs))
+	errs := make([]error, len(langs))
+	var wg sync.WaitGroup
+	for i, l := range langs {
+		if need[i] <= 0 {
+			continue
+		}
+		p := params
+		p.Lang = l.lang
+		p.Langs = nil
+		p.LangOffsets = nil
+		p.LangEnds = nil
+		p.StartIndex = l.offset + len(l.items)
+		wg.Add(1)
+		go func(i int, p SearchParams, count int) {
+			defer wg.Done()
+			results[i], errs[i] = s.fetchWindow(ctx, p, count)
+		}(i, p, need[i])
+	}
+	wg.Wait()
+
+	for i, l := range langs {
+		if need[i] <= 0 {
+			continue
+		}
+		if errs[i] != nil {
+			l.err = errs[i]
+			if merged.providerErrors == nil {
+				merged.providerErrors = make(map[string]string)
+			}
+			merged.providerErrors["lang:"+l.lang] = errs[i].Error()
+			continue
+		}
+		w := results[i]
+		l.fetched = true
+		l.items = append(l.items, w.items...)
+		l.total = max(w.total, l.offset+len(l.items))
+		if w.exhausted {
+			l.end = l.offset + len(l.items)
+			if w.end < l.end && params.LangOffsets == nil {
+				// startIndex から求めた位置が、その言語の結果より後ろだった
+				l.end = w.end
+			}
+		}
+		for name, msg := range w.providerErrors {
+			if merged.providerErrors == nil {
+				merged.providerErrors = make(map[string]string)
+			}
+			merged.providerErrors[name] = msg
+		}
+		if w.stale {
+			merged.stale = true
+			merged.staleAge = max(merged.staleAge, w.staleAge)
+		}
+	}
+	return true
+}
+
+// 全体の並びの順に、size 件になるまで取得済みの書籍を items に加える。
+// 同じ書籍は先に並んだものに不足分を補う。
+// 次に並べる言語の取得済みの分を使い切っていたら、取り直すために止まる
+func takeLanguages(langs []*languageState, items []Book, index map[string]int, size int) []Book {
+	for len(items) < size {
+		var l *languageState
+		for _, c := range langs {
+			if !c.done() && (l == nil || c.rank() < l.rank()) {
+				l = c
+			}
+		}
+		if l == nil || l.used == len(l.items) {
+			return items
+		}
+		b := l.items[l.used]
+		l.used++
+		if pos, ok := lookupAny(index, mergeKeys(b)); ok {
+			items[pos] = fillMissing(items[pos], b, b.Provider)
+			for _, k := range mergeKeys(items[pos]) {
+				index[k] = pos
+			}
+		} else {
+			for _, k := range mergeKeys(b) {
+				index[k] = len(items)
+			}
+			items = append(items, b)
+		}
+	}
+	return items
+}
+
+// 全体の並びで先頭から start 件を並べたとき、各言語を読み進めた位置。
+// ends[i] は言語 i の結果の件数（分からなければ -1）
+func languageOffsets(start int, ends []int) []int {
+	// 順位 r 未満の書籍の数
+	before := func(r int) int {
+		sum := 0
+		for _, e := range ends {
+			if e < 0 || e > r {
+				sum += r
+			} else {
+				sum += e
+			}
+		}
+		return sum
+	}
+	// before(r) <= start となる最大の r を求め、残りを順位 r の言語に順に割り当てる
+	r := sort.Search(start+1, func(r int) bool { return before(r+1) > start })
+	rest := start - before(r)
+	offsets := make([]int, len(ends))
+	for i, e := range ends {
+		offsets[i] = r
+		if e >= 0 && e < r {
+			offsets[i] = e
+		} else if (e < 0 || e > r) && rest > 0 {
+			offsets[i]++
+			rest--
+		}
+	}
+	return offsets
+}
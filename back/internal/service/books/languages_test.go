@@ -0,0 +1,203 @@
+package books
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// langClient has n books per language, with IDs like "ja-3".
+type langClient struct {
+	n map[string]int
+}
+
+func (c langClient) Search(ctx context.Context, p SearchParams) (SearchResult, error) {
+	res := SearchResult{TotalItems: c.n[p.Lang]}
+	for i := p.StartIndex; i < c.n[p.Lang] && i < p.StartIndex+p.MaxResults; i++ {
+		id := fmt.Sprintf("%s-%d", p.Lang, i)
+		res.Items = append(res.Items, Book{ID: id, Title: "title " + id, Language: p.Lang})
+	}
+	return res, nil
+}
+
+func (c langClient) Get(ctx context.Context, id string) (Book, error) {
+	return Book{}, ErrNotFound
+}
+
+func TestLanguageOffsets(t *testing.T) {
+	tests := []struct {
+		name  string
+		start int
+		ends  []int
+		want  []int
+	}{
+		{name: "start of the results", start: 0, ends: []int{-1, -1}, want: []int{0, 0}},
+		{name: "even split", start: 10, ends: []int{-1, -1}, want: []int{5, 5}},
+		{name: "earlier languages first", start: 4, ends: []int{-1, -1, -1}, want: []int{2, 1, 1}},
+		{name: "exhausted language gives up its positions", start: 10, ends: []int{3, -1}, want: []int{3, 7}},
+		{name: "before the language runs out", start: 5, ends: []int{3, -1}, want: []int{3, 2}},
+		{name: "empty language", start: 4, ends: []int{0, -1}, want: []int{0, 4}},
+		{name: "past the end of every language", start: 30, ends: []int{3, 14, 1}, want: []int{3, 14, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := languageOffsets(tt.start, tt.ends); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("languageOffsets(%d, %v) = %v, want %v", tt.start, tt.ends, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiLanguagePagesDoNotOverlap(t *testing.T) {
+	client := langClient{n: map[string]int{"ja": 50, "en": 50, "de": 50}}
+	s := NewService(client)
+	seen := make(map[string]bool)
+	for page := 0; page < 4; page++ {
+		res, err := s.Search(context.Background(), SearchParams{
+			Query: "go", Langs: []string{"ja", "en", "de"}, StartIndex: page * 10, MaxResults: 10, KeepEditions: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Items) != 10 {
+			t.Fatalf("page %d: %d items, want 10", page, len(res.Items))
+		}
+		for _, b := range res.Items {
+			if seen[b.ID] {
+				t.Fatalf("page %d: %s already returned", page, b.ID)
+			}
+			seen[b.ID] = true
+		}
+		if res.NextStartIndex != (page+1)*10 || !res.HasMore {
+			t.Fatalf("page %d: next %d, hasMore %v", page, res.NextStartIndex, res.HasMore)
+		}
+		if res.TotalItems != 150 || res.LanguageTotals["ja"] != 50 {
+			t.Fatalf("page %d: totals %d %v", page, res.TotalItems, res.LanguageTotals)
+		}
+	}
+	// ranks advance evenly: after 40 positions every language has given 13 or 14 books
+	for _, lang := range []string{"ja", "en", "de"} {
+		if !seen[lang+"-12"] || seen[lang+"-14"] {
+			t.Errorf("%s was not consumed in rank order", lang)
+		}
+	}
+}
+
+func TestMultiLanguageLastPage(t *testing.T) {
+	client := langClient{n: map[string]int{"ja": 3, "en": 8}}
+	s := NewService(client)
+	res, err := s.Search(context.Background(), SearchParams{Query: "go", Langs: []string{"ja", "en"}, MaxResults: 20, KeepEditions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Items) != 11 || res.HasMore || res.TotalItems != 11 {
+		t.Fatalf("items %d, hasMore %v, total %d", len(res.Items), res.HasMore, res.TotalItems)
+	}
+	if res.Items[0].ID != "ja-0" || res.Items[1].ID != "en-0" {
+		t.Fatalf("results are not interleaved: %s, %s", res.Items[0].ID, res.Items[1].ID)
+	}
+}
+
+func TestMultiLanguageCursorRefillsExhaustedLanguage(t *testing.T) {
+	client := langClient{n: map[string]int{"ja": 3, "en": 100}}
+	codec := NewCursorCodec([]byte("secret"))
+	s := NewService(client)
+	s.WithCursorCodec(codec)
+
+	params := SearchParams{Query: "go", Langs: []string{"ja", "en"}, MaxResults: 10, KeepEditions: true}
+	seen := make(map[string]bool)
+	for page := 0; page < 5; page++ {
+		res, err := s.Search(context.Background(), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Items) != 10 {
+			t.Fatalf("page %d: %d items, want 10", page, len(res.Items))
+		}
+		if res.TotalItems != 103 || res.LanguageTotals["ja"] != 3 || res.LanguageTotals["en"] != 100 || !res.HasMore {
+			t.Fatalf("page %d: total %d %v, hasMore %v", page, res.TotalItems, res.LanguageTotals, res.HasMore)
+		}
+		for _, b := range res.Items {
+			if seen[b.ID] {
+				t.Fatalf("page %d: %s already returned", page, b.ID)
+			}
+			seen[b.ID] = true
+		}
+		cur, err := codec.Decode(res.NextCursor)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		params = cur.SearchParams()
+	}
+	// 3 ja books and the first 47 en books, in rank order
+	for _, id := range []string{"ja-0", "ja-2", "en-0", "en-46"} {
+		if !seen[id] {
+			t.Errorf("%s was not returned", id)
+		}
+	}
+	if seen["en-47"] {
+		t.Errorf("en was not consumed in rank order")
+	}
+}
+
+func TestMultiLanguageCursorReachesTheEnd(t *testing.T) {
+	client := langClient{n: map[string]int{"ja": 3, "en": 14, "de": 1}}
+	codec := NewCursorCodec([]byte("secret"))
+	s := NewService(client)
+	s.WithCursorCodec(codec)
+
+	params := SearchParams{Query: "go", Langs: []string{"ja", "en", "de"}, MaxResults: 8, KeepEditions: true}
+	var sizes []int
+	for {
+		res, err := s.Search(context.Background(), params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(res.Items))
+		if res.TotalItems != 18 {
+			t.Fatalf("page %d: total %d, want 18", len(sizes)-1, res.TotalItems)
+		}
+		if !res.HasMore {
+			break
+		}
+		cur, err := codec.Decode(res.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params = cur.SearchParams()
+	}
+	if fmt.Sprint(sizes) != "[8 8 2]" {
+		t.Fatalf("page sizes = %v, want [8 8 2]", sizes)
+	}
+}
+
+func TestMultiLanguageStartIndexRefillsExhaustedLanguage(t *testing.T) {
+	client := langClient{n: map[string]int{"ja": 3, "en": 100}}
+	s := NewService(client)
+	seen := make(map[string]bool)
+	start := 0
+	for page := 0; page < 4; page++ {
+		res, err := s.Search(context.Background(), SearchParams{
+			Query: "go", Langs: []string{"ja", "en"}, StartIndex: start, MaxResults: 10, KeepEditions: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Items) != 10 {
+			t.Fatalf("page %d: %d items, want 10", page, len(res.Items))
+		}
+		for _, b := range res.Items {
+			if seen[b.ID] {
+				t.Fatalf("page %d: %s already returned", page, b.ID)
+			}
+			seen[b.ID] = true
+		}
+		if res.TotalItems != 103 {
+			t.Fatalf("page %d: total %d, want 103", page, res.TotalItems)
+		}
+		start = res.NextStartIndex
+	}
+	if !seen["en-36"] || seen["en-37"] {
+		t.Fatalf("40 positions should be ja-0..2 and en-0..36")
+	}
+}
@@ -0,0 +1,93 @@
+package books
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 検索パラメータを正規化する（キャッシュキーや比較に利用）。
+// 自由入力の語句は NFKC で正規化し、全角英数字や全角空白の表記ゆれをなくす
+func (p SearchParams) Normalize() SearchParams {
+	p.Query = NormalizeText(p.Query)
+	if p.StartIndex < 0 {
+		p.StartIndex = 0
+	}
+	if p.MaxResults < 0 {
+		p.MaxResults = 0
+	}
+	if p.OrderBy != "newest" {
+		p.OrderBy = "relevance"
+	}
+	p.Title = NormalizeText(p.Title)
+	p.Author = NormalizeText(p.Author)
+	p.Publisher = NormalizeText(p.Publisher)
+	p.Subject = NormalizeText(p.Subject)
+	p.ISBN = NormalizeText(p.ISBN)
+	p.ExactPhrase = NormalizeText(p.ExactPhrase)
+	var exclude []string
+	for _, ex := range p.Exclude {
+		if ex = NormalizeText(ex); ex != "" {
+			exclude = append(exclude, ex)
+		}
+	}
+	p.Exclude = exclude
+	p.TagQueries = mapStrings(p.TagQueries, NormalizeText)
+	p.EbookFilter = strings.ToLower(strings.TrimSpace(p.EbookFilter))
+	p.Download = strings.ToLower(strings.TrimSpace(p.Download))
+	p.Lang = strings.ToLower(strings.TrimSpace(p.Lang))
+	if p.Lang == "all" {
+		p.Lang = ""
+	}
+	// 複数言語は重複を除き、1つだけなら Lang にまとめる。"all" を含む場合は言語を絞らない
+	var langs []string
+	seen := make(map[string]bool)
+	for _, l := range p.Langs {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "all" {
+			langs, p.Lang = nil, ""
+			break
+		}
+		if l != "" && !seen[l] {
+			seen[l] = true
+			langs = append(langs, l)
+		}
+	}
+	p.Langs = nil
+	switch {
+	case len(langs) == 1:
+		p.Lang = langs[0]
+	case len(langs) > 1:
+		p.Lang = ""
+		p.Langs = langs
+	}
+	return p
+}
+
+// 正規化済みパラメータから一意なキー文字列を生成する
+func (p SearchParams) Key() string {
+	n := p.Normalize()
+	v := url.Values{}
+	v.Set("q", n.Query)
+	v.Set("start", strconv.Itoa(n.StartIndex))
+	v.Set("max", strconv.Itoa(n.MaxResults))
+	v.Set("order", n.OrderBy)
+	v.Set("lang", n.Lang)
+	v["langs"] = n.Langs
+	v["tags"] = n.Tags
+	v["tagq"] = n.TagQueries
+	v.Set("title", n.Title)
+	v.Set("author", n.Author)
+	v.Set("publisher", n.Publisher)
+	v.Set("subject", n.Subject)
+	v.Set("isbn", n.ISBN)
+	v.Set("phrase", n.ExactPhrase)
+	v["exclude"] = n.Exclude
+	v.Set("filter", n.EbookFilter)
+	v.Set("download", n.Download)
+	return v.Encode()
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -7,4 +7,12 @@ import (
 // 書籍検索クライアント用インターフェース。
 type ExternalClient interface {
 	Search(ctx context.Context, params SearchParams) (SearchResult, error)
+	// ID で1冊を取得する。存在しない場合は ErrNotFound を返す
+	Get(ctx context.Context, id string) (Book, error)
+}
+
+// ISBN をキーに書誌情報を補う提供元のインターフェース。
+// 戻り値のキーは ISBN-13 で、見つからない ISBN は含めない。
+type MetadataProvider interface {
+	LookupISBNs(ctx context.Context, isbns []string) (map[string]Book, error)
 }
@@ -0,0 +1,49 @@
+package books
+
+// 電子書籍・閲覧範囲による絞り込み（Google Books の filter パラメータ）
+const (
+	EbookFilterPartial = "partial"     // 一部でも試し読みできる
+	EbookFilterFull    = "full"        // 全文を読める
+	EbookFilterFree    = "free-ebooks" // 無料の電子書籍
+	EbookFilterPaid    = "paid-ebooks" // 有料の電子書籍
+	EbookFilterEbooks  = "ebooks"      // 電子書籍（有料・無料とも）
+)
+
+// ダウンロード形式による絞り込み（Google Books の download パラメータ）
+const DownloadEpub = "epub"
+
+// 対応している filter の値か
+func ValidEbookFilter(v string) bool {
+	switch v {
+	case EbookFilterPartial, EbookFilterFull, EbookFilterFree, EbookFilterPaid, EbookFilterEbooks:
+		return true
+	}
+	return false
+}
+
+// 販売情報（Google の saleInfo）
+type SaleInfo struct {
+	Country     string `json:"Country,omitempty"`
+	Saleability string `json:"Saleability,omitempty"` // FOR_SALE | FREE | NOT_FOR_SALE | FOR_PREORDER
+	IsEbook     bool   `json:"IsEbook,omitempty"`
+	ListPrice   *Price `json:"ListPrice,omitempty"`   // 定価
+	RetailPrice *Price `json:"RetailPrice,omitempty"` // 販売価格
+	BuyLink     string `json:"BuyLink,omitempty"`
+}
+
+// 価格と通貨（ISO 4217）
+type Price struct {
+	Amount       float64
+	CurrencyCode string
+}
+
+// 閲覧・ダウンロードの可否（Google の accessInfo）
+type AccessInfo struct {
+	Viewability      string `json:"Viewability,omitempty"` // NO_PAGES | PARTIAL | ALL_PAGES
+	PublicDomain     bool   `json:"PublicDomain,omitempty"`
+	EpubAvailable    bool   `json:"EpubAvailable,omitempty"`
+	PdfAvailable     bool   `json:"PdfAvailable,omitempty"`
+	EpubDownloadLink string `json:"EpubDownloadLink,omitempty"`
+	PdfDownloadLink  string `json:"PdfDownloadLink,omitempty"`
+	WebReaderLink    string `json:"WebReaderLink,omitempty"`
+}
@@ -2,20 +2,260 @@ package books
 
 import (
 	"context"
+	"strings"
 )
 
 // 技術書検索サービス用タイプ
 type Service struct {
-	client ExternalClient
+	client   ExternalClient
+	taxonomy *Taxonomy
+	filters  FilterOptions
+	extra    []Filter
+	paging   PagingOptions
+	cursors  *CursorCodec
+	metadata MetadataProvider
+	// Sources に記録する metadata の提供元名
+	metadataName string
+	// 検索語のかなの寄せ方
+	kana KanaFold
 }
 
 // 技術書検索サービスの生成メソッド
 func NewService(client ExternalClient) *Service {
-	return &Service{client: client}
+	return &Service{client: client, taxonomy: DefaultTaxonomy(), paging: DefaultPagingOptions()}
+}
+
+// 検索語のかなをカタカナ・ひらがなのどちらかにそろえる（KanaFoldNone で無効）
+func (s *Service) WithKanaFolding(f KanaFold) {
+	s.kana = f
+}
+
+// ページング設定を差し替える（0 以下の項目はデフォルトのまま）
+func (s *Service) WithPaging(opts PagingOptions) {
+	def := DefaultPagingOptions()
+	if opts.UpstreamPageSize <= 0 {
+		opts.UpstreamPageSize = def.UpstreamPageSize
+	}
+	if opts.MaxPageSize <= 0 {
+		opts.MaxPageSize = def.MaxPageSize
+	}
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = def.Parallelism
+	}
+	if opts.MaxFacetWindow <= 0 {
+		opts.MaxFacetWindow = def.MaxFacetWindow
+	}
+	s.paging = opts
+}
+
+// 検索結果に次・前ページのカーソルを付与する（nil で無効化）
+func (s *Service) WithCursorCodec(c *CursorCodec) {
+	s.cursors = c
+}
+
+// カーソル文字列を検索パラメータに戻す
+func (s *Service) ParseCursor(token string) (SearchParams, error) {
+	if s.cursors == nil {
+		return SearchParams{}, ErrInvalidCursor
+	}
+	cur, err := s.cursors.Decode(token)
+	if err != nil {
+		return SearchParams{}, err
+	}
+	return cur.SearchParams(), nil
+}
+
+// ISBN を持つ書籍の情報を補完する提供元を設定する（nil で無効化）
+func (s *Service) WithMetadataProvider(name string, p MetadataProvider) {
+	s.metadata = p
+	s.metadataName = name
+}
+
+// 1リクエストで返せる最大件数
+func (s *Service) MaxPageSize() int {
+	return s.paging.MaxPageSize
+}
+
+// ファセット集計に指定できる最大件数
+func (s *Service) MaxFacetWindow() int {
+	return s.paging.MaxFacetWindow
+}
+
+// 技術タグの分類体系を差し替える
+func (s *Service) WithTaxonomy(t *Taxonomy) {
+	if t != nil {
+		s.taxonomy = t
+	}
+}
+
+// サーバー全体のデフォルトフィルタ設定を差し替える
+func (s *Service) WithDefaultFilters(opts FilterOptions) {
+	s.filters = opts
+}
+
+// 組み込み以外のフィルタを常に適用するよう追加する
+func (s *Service) WithFilters(filters ...Filter) {
+	s.extra = append(s.extra, filters...)
+}
+
+// 技術タグの一覧
+func (s *Service) Tags() []Tag {
+	return s.taxonomy.Tags()
 }
 
 // 技術書検索メソッド
 func (s *Service) Search(ctx context.Context, params SearchParams) (SearchResult, error) {
-	// ToDO: フィルタリングなどの実装
-	return s.client.Search(ctx, params)
+	terms, err := s.taxonomy.Expand(params.Tags)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	params.TagQueries = terms
+	// 上流への問い合わせ・キャッシュキーの前に表記ゆれをそろえる
+	params = params.Normalize().foldKana(s.kana)
+	if params.ISBN != "" {
+		isbn13, err := NormalizeISBN(params.ISBN)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		params.ISBN = isbn13
+	}
+
+	size := params.MaxResults
+	if size <= 0 {
+		size = s.paging.UpstreamPageSize
+	}
+	size = min(size, s.paging.MaxPageSize)
+
+	w, err := s.window(ctx, params, size)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	res := SearchResult{
+		TotalItems:      w.total,
+		Items:           s.enrich(ctx, w.items),
+		NextStartIndex:  w.next,
+		HasMore:         !w.exhausted && w.next < w.total,
+		ProviderErrors:  w.providerErrors,
+		Stale:           w.stale,
+		StaleAgeSeconds: w.staleAge,
+		LanguageTotals:  w.languageTotals,
+	}
+
+	before := len(res.Items)
+	res.Items, res.FilteredBy = applyFilters(res.Items, s.filtersFor(params))
+	res.FilteredOut = before - len(res.Items)
+	if !params.KeepEditions {
+		res.Items, res.MergedEditions = groupEditions(res.Items)
+	}
+	res.Facets = s.facetsFor(ctx, params, w, res.Items)
+
+	if err := s.attachCursors(&res, params, size, w); err != nil {
+		return SearchResult{}, err
+	}
+	return res, nil
+}
+
+// 次ページ・前ページのカーソルを作る。次ページには今回返した ID を重複排除用に、
+// 複数言語の検索では各言語を読み進めた位置と分かった件数を引き継ぐ
+func (s *Service) attachCursors(res *SearchResult, params SearchParams, size int, w window) error {
+	if s.cursors == nil {
+		return nil
+	}
+	base := params
+	base.StartIndex = 0
+	base.MaxResults = size
+	base.TagQueries = nil
+
+	if res.HasMore {
+		seen := append([]string(nil), params.SeenIDs...)
+		for _, b := range res.Items {
+			seen = append(seen, seenHash(b.ID))
+			for _, id := range b.AlternateEditionIDs {
+				seen = append(seen, seenHash(id))
+			}
+		}
+		if len(seen) > maxSeenIDs {
+			seen = seen[len(seen)-maxSeenIDs:]
+		}
+		next, err := s.cursors.Encode(Cursor{Params: base, Offset: res.NextStartIndex, Seen: seen, Langs: w.langOffsets, Ends: w.langEnds})
+		if err != nil {
+			return err
+		}
+		res.NextCursor = next
+	}
+	if params.StartIndex > 0 {
+		prev, err := s.cursors.Encode(Cursor{Params: base, Offset: max(0, params.StartIndex-size), Ends: w.langEnds})
+		if err != nil {
+			return err
+		}
+		res.PrevCursor = prev
+	}
+	return nil
+}
+
+// リクエストに適用するフィルタの列（デフォルト設定＋リクエスト指定＋追加フィルタ）
+func (s *Service) filtersFor(params SearchParams) []Filter {
+	opts := s.filters
+	if params.Filters != nil {
+		opts = params.Filters.Merge(s.filters)
+	}
+	var filters []Filter
+	if len(params.SeenIDs) > 0 {
+		filters = append(filters, seenFilter(params.SeenIDs))
+	}
+	if params.PublishedAfter != nil || params.PublishedBefore != nil {
+		filters = append(filters, publishedRangeFilter(params.PublishedAfter, params.PublishedBefore))
+	}
+	if !opts.Disabled {
+		filters = append(filters, opts.Filters()...)
+		filters = append(filters, s.extra...)
+	}
+	return append(filters, params.ExtraFilters...)
+}
+
+// 前ページまでに返した書籍を除くフィルタ（上流の totalItems の揺れによる重複対策）
+func seenFilter(hashes []string) Filter {
+	seen := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		seen[h] = true
+	}
+	return Filter{Name: "duplicate", Keep: func(b Book) bool {
+		return !seen[seenHash(b.ID)]
+	}}
+}
+
+// ボリューム ID で1冊を取得するメソッド
+func (s *Service) Get(ctx context.Context, id string) (Book, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return Book{}, ErrNotFound
+	}
+	book, err := s.client.Get(ctx, id)
+	if err != nil {
+		return Book{}, err
+	}
+	return s.enrich(ctx, []Book{book})[0], nil
+}
+
+// ISBN で1冊を引くメソッド（ISBN-10 / ISBN-13 どちらでも可）
+func (s *Service) LookupISBN(ctx context.Context, isbn string) (Book, error) {
+	isbn13, err := NormalizeISBN(isbn)
+	if err != nil {
+		return Book{}, err
+	}
+
+	res, err := s.client.Search(ctx, SearchParams{
+		ISBN:       isbn13,
+		MaxResults: 10,
+	})
+	if err != nil {
+		return Book{}, err
+	}
+	// isbn: 検索でも別の本が混ざることがあるため、ISBN が一致するものだけを採用する
+	for _, b := range res.Items {
+		if b.ISBN13 == isbn13 {
+			return s.enrich(ctx, []Book{b})[0], nil
+		}
+	}
+	return Book{}, ErrNotFound
 }
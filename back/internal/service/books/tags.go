@@ -0,0 +1,118 @@
+package books
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 未定義のタグが指定された
+var ErrUnknownTag = errors.New("unknown tag")
+
+// 技術タグ。Queries は OR でまとめて検索する語句の束
+type Tag struct {
+	Key     string
+	Label   string
+	Queries []string
+}
+
+// 技術タグの分類体系
+type Taxonomy struct {
+	tags  []Tag
+	byKey map[string]Tag
+}
+
+// タグ一覧から分類体系を作る。キーの重複や語句の無いタグはエラー
+func NewTaxonomy(tags []Tag) (*Taxonomy, error) {
+	t := &Taxonomy{byKey: make(map[string]Tag, len(tags))}
+	for _, tag := range tags {
+		tag.Key = strings.TrimSpace(tag.Key)
+		if tag.Key == "" {
+			return nil, fmt.Errorf("tag key is required")
+		}
+		if _, dup := t.byKey[tag.Key]; dup {
+			return nil, fmt.Errorf("duplicate tag key %q", tag.Key)
+		}
+		var queries []string
+		for _, q := range tag.Queries {
+			if q = strings.TrimSpace(q); q != "" {
+				queries = append(queries, q)
+			}
+		}
+		if len(queries) == 0 {
+			return nil, fmt.Errorf("tag %q has no queries", tag.Key)
+		}
+		tag.Queries = queries
+		if tag.Label == "" {
+			tag.Label = tag.Key
+		}
+		t.tags = append(t.tags, tag)
+		t.byKey[tag.Key] = tag
+	}
+	return t, nil
+}
+
+// 定義順のタグ一覧
+func (t *Taxonomy) Tags() []Tag {
+	return append([]Tag(nil), t.tags...)
+}
+
+// キーからタグを引く
+func (t *Taxonomy) Lookup(key string) (Tag, bool) {
+	tag, ok := t.byKey[key]
+	return tag, ok
+}
+
+// タグキーの一覧を検索語句の一覧へ展開する（重複は除く）
+func (t *Taxonomy) Expand(keys []string) ([]string, error) {
+	var (
+		terms []string
+		seen  = make(map[string]bool)
+	)
+	for _, key := range keys {
+		tag, ok := t.Lookup(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTag, key)
+		}
+		for _, q := range tag.Queries {
+			if !seen[q] {
+				seen[q] = true
+				terms = append(terms, q)
+			}
+		}
+	}
+	return terms, nil
+}
+
+// 組み込みの技術タグ（フロントエンドは GET /api/tags で取得する）
+func DefaultTaxonomy() *Taxonomy {
+	t, err := NewTaxonomy([]Tag{
+		{Key: "computer-science", Label: "Computer Science", Queries: []string{"Computer Science"}},
+		{Key: "network", Label: "Network", Queries: []string{"computer networks", "network protocols"}},
+		{Key: "database", Label: "Database", Queries: []string{"databases", "database systems", "SQL"}},
+		{Key: "operating-system", Label: "Operating System", Queries: []string{"operating systems", "linux"}},
+		{Key: "software-architecture", Label: "Software Architecture", Queries: []string{"software architecture", "system design"}},
+		{Key: "oop", Label: "Object-Oriented Programming", Queries: []string{"object-oriented programming", "OOP"}},
+		{Key: "data-structure", Label: "Data Structure", Queries: []string{"data structures"}},
+		{Key: "algorithm", Label: "Algorithm", Queries: []string{"algorithms", "algorithm design"}},
+		{Key: "software-test", Label: "Software Test", Queries: []string{"software testing", "test automation", "unit testing"}},
+		{Key: "design-pattern", Label: "Design Pattern", Queries: []string{"design patterns", "software patterns"}},
+		{Key: "git", Label: "Git/GitHub", Queries: []string{"Git", "GitHub"}},
+		{Key: "discrete-math", Label: "Discrete Mathematics", Queries: []string{"discrete mathematics"}},
+		{Key: "html-css", Label: "HTML & CSS", Queries: []string{"HTML", "CSS"}},
+		{Key: "javascript", Label: "JavaScript", Queries: []string{"JavaScript"}},
+		{Key: "vue", Label: "Vue", Queries: []string{"Vue", "Vue.js", "VueJS"}},
+		{Key: "django", Label: "Django", Queries: []string{"Django"}},
+		{Key: "react", Label: "React", Queries: []string{"React", "React.js", "ReactJS"}},
+		{Key: "laravel", Label: "Laravel", Queries: []string{"Laravel"}},
+		{Key: "angular", Label: "Angular", Queries: []string{"Angular"}},
+		{Key: "rails", Label: "Ruby on Rails", Queries: []string{"Ruby on Rails", "Rails"}},
+		{Key: "unity", Label: "Unity", Queries: []string{"Unity"}},
+		{Key: "swiftui", Label: "Swift UI", Queries: []string{"SwiftUI", "Swift UI"}},
+		{Key: "uikit", Label: "UIKit (iOS)", Queries: []string{"UIKit", "iOS"}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
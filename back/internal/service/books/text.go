@@ -0,0 +1,83 @@
+package books
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// カタカナ・ひらがなの表記ゆれをそろえる方向
+type KanaFold string
+
+const (
+	KanaFoldNone     KanaFold = ""         // そろえない
+	KanaFoldKatakana KanaFold = "katakana" // ひらがなをカタカナにそろえる（技術用語向け）
+	KanaFoldHiragana KanaFold = "hiragana" // カタカナをひらがなにそろえる
+)
+
+// 設定値からかなの寄せ方を解釈する（"off" / 空はそろえない）
+func ParseKanaFold(s string) (KanaFold, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "", "off", "none":
+		return KanaFoldNone, nil
+	case string(KanaFoldKatakana), string(KanaFoldHiragana):
+		return KanaFold(v), nil
+	}
+	return KanaFoldNone, fmt.Errorf("unknown kana folding %q (want katakana, hiragana or off)", s)
+}
+
+// 入力文字列を NFKC で正規化し、空白を1つに詰める。
+// 全角英数字・全角空白・半角カタカナは NFKC で通常の文字になる（例: "Ｇｏ言語" → "Go言語"）
+func NormalizeText(s string) string {
+	return collapse(norm.NFKC.String(s))
+}
+
+// かなを指定の方向にそろえる。長音符「ー」や記号はそのまま
+func (f KanaFold) Apply(s string) string {
+	switch f {
+	case KanaFoldKatakana:
+		return strings.Map(func(r rune) rune {
+			// ぁ(U+3041)〜ゖ(U+3096) と ゝゞ を対応するカタカナへ
+			if (r >= 'ぁ' && r <= 'ゖ') || r == 'ゝ' || r == 'ゞ' {
+				return r + 0x60
+			}
+			return r
+		}, s)
+	case KanaFoldHiragana:
+		return strings.Map(func(r rune) rune {
+			if (r >= 'ァ' && r <= 'ヶ') || r == 'ヽ' || r == 'ヾ' {
+				return r - 0x60
+			}
+			return r
+		}, s)
+	}
+	return s
+}
+
+// 検索語の各フィールドにかなの寄せ方を適用する
+func (p SearchParams) foldKana(f KanaFold) SearchParams {
+	if f == KanaFoldNone {
+		return p
+	}
+	p.Query = f.Apply(p.Query)
+	p.Title = f.Apply(p.Title)
+	p.Author = f.Apply(p.Author)
+	p.Publisher = f.Apply(p.Publisher)
+	p.Subject = f.Apply(p.Subject)
+	p.ExactPhrase = f.Apply(p.ExactPhrase)
+	p.Exclude = mapStrings(p.Exclude, f.Apply)
+	p.TagQueries = mapStrings(p.TagQueries, f.Apply)
+	return p
+}
+
+func mapStrings(values []string, fn func(string) string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = fn(v)
+	}
+	return out
+}
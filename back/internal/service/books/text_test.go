@@ -0,0 +1,139 @@
+package books
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Ｇｏ言語", "Go言語"},
+		{"Ｇｏ　言語", "Go 言語"},
+		{"  Go　　言語  入門 ", "Go 言語 入門"},
+		{"Ｐｙｔｈｏｎ３", "Python3"},
+		{"第２版", "第2版"},
+		{"ﾃﾞｰﾀﾍﾞｰｽ", "データベース"},
+		{"ｺﾝﾃﾅ ｵｰｹｽﾄﾚｰｼｮﾝ", "コンテナ オーケストレーション"},
+		{"Ｃ＋＋", "C++"},
+		{"ＳＱＬ（入門）", "SQL(入門)"},
+		{"Kubernetes", "Kubernetes"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.in); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKanaFoldApply(t *testing.T) {
+	tests := []struct {
+		fold KanaFold
+		in   string
+		want string
+	}{
+		{KanaFoldKatakana, "でーたべーす", "データベース"},
+		{KanaFoldKatakana, "ぷろぐらみんぐ Go", "プログラミング Go"},
+		{KanaFoldKatakana, "ゝゞ", "ヽヾ"},
+		{KanaFoldKatakana, "ぁゖ", "ァヶ"},
+		{KanaFoldKatakana, "データベース設計", "データベース設計"},
+		{KanaFoldHiragana, "データベース", "でーたべーす"},
+		{KanaFoldHiragana, "ヽヾ", "ゝゞ"},
+		{KanaFoldHiragana, "ァヶ", "ぁゖ"},
+		{KanaFoldHiragana, "コンテナ入門", "こんてな入門"},
+		{KanaFoldNone, "でーたべーす", "でーたべーす"},
+	}
+	for _, tt := range tests {
+		if got := tt.fold.Apply(tt.in); got != tt.want {
+			t.Errorf("%q.Apply(%q) = %q, want %q", tt.fold, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKanaFoldRoundTrip(t *testing.T) {
+	for _, s := range []string{"データベース", "プログラミング", "ヽヾ", "コンテナ・オーケストレーション"} {
+		if got := KanaFoldKatakana.Apply(KanaFoldHiragana.Apply(s)); got != s {
+			t.Errorf("round trip of %q = %q", s, got)
+		}
+	}
+}
+
+func TestParseKanaFold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    KanaFold
+		wantErr bool
+	}{
+		{"", KanaFoldNone, false},
+		{"off", KanaFoldNone, false},
+		{"none", KanaFoldNone, false},
+		{"katakana", KanaFoldKatakana, false},
+		{" Hiragana ", KanaFoldHiragana, false},
+		{"romaji", KanaFoldNone, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseKanaFold(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseKanaFold(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDifferentlyWrittenQueriesShareKey(t *testing.T) {
+	pairs := []struct {
+		a, b SearchParams
+		fold KanaFold
+	}{
+		{SearchParams{Query: "Ｇｏ言語"}, SearchParams{Query: "Go言語"}, KanaFoldNone},
+		{SearchParams{Query: "Ｇｏ　言語　入門"}, SearchParams{Query: " Go 言語  入門"}, KanaFoldNone},
+		{SearchParams{Title: "ﾃﾞｰﾀﾍﾞｰｽ"}, SearchParams{Title: "データベース"}, KanaFoldNone},
+		{SearchParams{Query: "でーたべーす"}, SearchParams{Query: "ﾃﾞｰﾀﾍﾞｰｽ"}, KanaFoldKatakana},
+		{SearchParams{Exclude: []string{"じゃば"}}, SearchParams{Exclude: []string{"ジャバ"}}, KanaFoldKatakana},
+	}
+	for _, p := range pairs {
+		a := p.a.Normalize().foldKana(p.fold).Key()
+		b := p.b.Normalize().foldKana(p.fold).Key()
+		if a != b {
+			t.Errorf("keys differ:\n%s\n%s", a, b)
+		}
+	}
+	if a, b := (SearchParams{Query: "でーたべーす"}).Key(), (SearchParams{Query: "データベース"}).Key(); a == b {
+		t.Error("kana should only be folded when folding is enabled")
+	}
+}
+
+// recordingClient remembers the key of the last search it received.
+type recordingClient struct {
+	lastKey string
+}
+
+func (c *recordingClient) Search(ctx context.Context, p SearchParams) (SearchResult, error) {
+	c.lastKey = p.Key()
+	return SearchResult{}, nil
+}
+
+func (c *recordingClient) Get(ctx context.Context, id string) (Book, error) {
+	return Book{}, ErrNotFound
+}
+
+func TestServiceSendsNormalizedQueries(t *testing.T) {
+	client := &recordingClient{}
+	s := NewService(client)
+	s.WithKanaFolding(KanaFoldKatakana)
+
+	var keys []string
+	for _, q := range []string{"ﾃﾞｰﾀﾍﾞｰｽ　設計", "でーたべーす 設計", "データベース  設計"} {
+		if _, err := s.Search(context.Background(), SearchParams{Query: q}); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, client.lastKey)
+	}
+	for _, k := range keys[1:] {
+		if k != keys[0] {
+			t.Fatalf("upstream keys differ: %q", keys)
+		}
+	}
+}
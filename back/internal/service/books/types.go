@@ -1,15 +1,63 @@
 package books
 
+import "strings"
+
 // 書籍検索の入力パラメータ
 type SearchParams struct {
-	Query      string
-	StartIndex int
-	MaxResults int
-	OrderBy    string // "relevance" | "newest"
-	Lang       string
+	Query      string   `json:"Query,omitempty"`
+	StartIndex int      `json:"StartIndex,omitempty"`
+	MaxResults int      `json:"MaxResults,omitempty"`
+	OrderBy    string   `json:"OrderBy,omitempty"` // "relevance" | "newest"
+	Lang       string   `json:"Lang,omitempty"`
+	Langs      []string `json:"Langs,omitempty"`      // 複数言語を並列に検索する場合（2つ以上のときのみ）
+	Tags       []string `json:"Tags,omitempty"`       // 技術タグのキー
+	TagQueries []string `json:"TagQueries,omitempty"` // Tags を展開した検索語句（Service が設定する）
+
+	// 構造化検索（それぞれ intitle: などの演算子に変換される）
+	Title       string   `json:"Title,omitempty"`
+	Author      string   `json:"Author,omitempty"`
+	Publisher   string   `json:"Publisher,omitempty"`
+	Subject     string   `json:"Subject,omitempty"`
+	ISBN        string   `json:"ISBN,omitempty"` // Service が ISBN-13 に正規化する
+	ExactPhrase string   `json:"ExactPhrase,omitempty"`
+	Exclude     []string `json:"Exclude,omitempty"` // 含めたくない語
+
+	// 電子書籍・閲覧範囲の絞り込み（EbookFilter* のいずれか）と、ダウンロード形式（"epub"）
+	EbookFilter string `json:"EbookFilter,omitempty"`
+	Download    string `json:"Download,omitempty"`
+
+	// 出版日の範囲（両端を含む）
+	PublishedAfter  *Date `json:"PublishedAfter,omitempty"`
+	PublishedBefore *Date `json:"PublishedBefore,omitempty"`
+	// true なら同じ作品の別版をまとめずに返す
+	KeepEditions bool `json:"KeepEditions,omitempty"`
+	// ファセット集計に使う件数（表示件数より大きければ後続の結果も取得して集計する）
+	FacetWindow int `json:"FacetWindow,omitempty"`
+
+	// 結果フィルタ（nil ならサーバーのデフォルト）
+	Filters *FilterOptions `json:"Filters,omitempty"`
+	// 呼び出し元のライブラリで除外する状態（favorites / stacked など）。
+	// handler が ExtraFilters に変換する。カーソルで引き継ぐために保持する
+	ExcludeLibrary []string `json:"ExcludeLibrary,omitempty"`
+	// リクエスト固有の追加フィルタ（JSON には含めない）
+	ExtraFilters []Filter `json:"-"`
+	// 前ページまでに返した ID のハッシュ（カーソル由来、JSON には含めない）
+	SeenIDs []string `json:"-"`
+	// 複数言語の検索で各言語を読み始める位置と、分かっている各言語の件数（カーソル由来、JSON には含めない）
+	LangOffsets map[string]int `json:"-"`
+	LangEnds    map[string]int `json:"-"`
+}
+
+// 自由入力以外に検索条件が指定されているか
+func (p SearchParams) HasCriteria() bool {
+	return strings.TrimSpace(p.Query) != "" || len(p.Tags) > 0 || len(p.TagQueries) > 0 ||
+		strings.TrimSpace(p.Title) != "" || strings.TrimSpace(p.Author) != "" ||
+		strings.TrimSpace(p.Publisher) != "" || strings.TrimSpace(p.Subject) != "" ||
+		strings.TrimSpace(p.ISBN) != "" || strings.TrimSpace(p.ExactPhrase) != ""
 }
 
 // 検索結果の1件分
+// 後から追加したフィールドは omitempty とし、保存済みの JSON と互換を保つ
 type Book struct {
 	ID            string
 	Title         string
@@ -20,10 +68,53 @@ type Book struct {
 	PageCount     int
 	Thumbnail     string
 	InfoLink      string
+	ISBN10        string      `json:"ISBN10,omitempty"`
+	ISBN13        string      `json:"ISBN13,omitempty"`
+	Subtitle      string      `json:"Subtitle,omitempty"`
+	Publisher     string      `json:"Publisher,omitempty"`
+	Language      string      `json:"Language,omitempty"`
+	AverageRating float64     `json:"AverageRating,omitempty"`
+	RatingsCount  int         `json:"RatingsCount,omitempty"`
+	PreviewLink   string      `json:"PreviewLink,omitempty"`
+	Images        *ImageLinks `json:"Images,omitempty"`
+	TextSnippet   string      `json:"TextSnippet,omitempty"`
+	Sale          *SaleInfo   `json:"Sale,omitempty"`
+	Access        *AccessInfo `json:"Access,omitempty"`
+	Published     *Date       `json:"Published,omitempty"` // PublishedDate を解釈したもの
+	// 同じ作品の別版の ID（検索結果で版をまとめた場合のみ）
+	AlternateEditionIDs []string `json:"AlternateEditionIDs,omitempty"`
+	// 書籍の取得元プロバイダ名と、他の提供元で補ったフィールド（フィールド名 → 提供元）
+	Provider string            `json:"Provider,omitempty"`
+	Sources  map[string]string `json:"Sources,omitempty"`
+}
+
+// 表紙画像のサイズ別 URL（Google が返したものだけが入る）
+type ImageLinks struct {
+	SmallThumbnail string `json:"SmallThumbnail,omitempty"`
+	Thumbnail      string `json:"Thumbnail,omitempty"`
+	Small          string `json:"Small,omitempty"`
+	Medium         string `json:"Medium,omitempty"`
+	Large          string `json:"Large,omitempty"`
+	ExtraLarge     string `json:"ExtraLarge,omitempty"`
 }
 
 // 検索結果
 type SearchResult struct {
-	TotalItems int
-	Items      []Book
+	TotalItems     int
+	Items          []Book
+	NextStartIndex int            // 次ページの startIndex
+	HasMore        bool           // 続きがあるか
+	FilteredOut    int            // フィルタで除外した件数
+	FilteredBy     map[string]int `json:",omitempty"` // フィルタ名ごとの除外件数
+	MergedEditions int            `json:",omitempty"` // 別版としてまとめた件数
+	Facets         *Facets        `json:",omitempty"` // 絞り込み用の集計
+	// 失敗したプロバイダと理由（一部のプロバイダだけ失敗した場合）
+	ProviderErrors map[string]string `json:",omitempty"`
+	// 上流の障害時に保存済みの応答で代用した場合 true と、その応答の経過秒数
+	Stale           bool `json:",omitempty"`
+	StaleAgeSeconds int  `json:",omitempty"`
+	// 複数言語で検索した場合の言語ごとの総件数
+	LanguageTotals map[string]int `json:",omitempty"`
+	NextCursor     string         `json:",omitempty"` // 次ページ用の不透明なカーソル
+	PrevCursor     string         `json:",omitempty"` // 前ページ用の不透明なカーソル
 }
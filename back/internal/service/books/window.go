@@ -0,0 +1,143 @@
+package books
+
+import (
+	"context"
+	"sync"
+)
+
+// ページングの設定
+type PagingOptions struct {
+	// 上流に1回で要求する件数
+	UpstreamPageSize int
+	// 1リクエストで返せる最大件数
+	MaxPageSize int
+	// 上流ページを同時に取得する数の上限
+	Parallelism int
+	// ファセット集計のために取得してよい最大件数
+	MaxFacetWindow int
+}
+
+// デフォルトのページング設定
+func DefaultPagingOptions() PagingOptions {
+	return PagingOptions{
+		UpstreamPageSize: 10,
+		MaxPageSize:      40,
+		Parallelism:      4,
+		MaxFacetWindow:   200,
+	}
+}
+
+// 複数の上流ページをまとめた取得結果
+type window struct {
+	items []Book
+	// 上流が報告した総件数（終端に達した場合は実数）
+	total int
+	// 次に読むべき上流のオフセット
+	next int
+	// 上流の結果を最後まで読み切ったか。読み切った場合の結果の件数
+	exhausted bool
+	end       int
+	// 一部のプロバイダが失敗したページの理由
+	providerErrors map[string]string
+	// 保存済みの応答で代用したページがあったか（経過秒数は最も古いもの）
+	stale    bool
+	staleAge int
+	// 複数言語で検索した場合の言語ごとの総件数と、次ページで各言語を読み始める位置・分かった件数
+	languageTotals map[string]int
+	langOffsets    map[string]int
+	langEnds       map[string]int
+}
+
+type pageResult struct {
+	res SearchResult
+	err error
+}
+
+// params.StartIndex から size 件分を上流ページに分けて並列に取得し、順序どおりに連結する。
+// 重複する ID は最初の1件だけを残す。2ページ目以降の失敗はそこまでの結果で打ち切る。
+func (s *Service) fetchWindow(ctx context.Context, params SearchParams, size int) (window, error) {
+	up := s.paging.UpstreamPageSize
+	start := params.StartIndex
+	pages := (size + up - 1) / up
+
+	results := make([]pageResult, pages)
+	sem := make(chan struct{}, max(1, s.paging.Parallelism))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < pages; i++ {
+		p := params
+		p.StartIndex = start + i*up
+		p.MaxResults = min(up, size-i*up)
+		wg.Add(1)
+		go func(i int, p SearchParams) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			results[i].res, results[i].err = s.client.Search(ctx, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	w := window{next: start}
+	seen := make(map[string]bool)
+	for i, r := range results {
+		if r.err != nil {
+			if i == 0 {
+				return window{}, r.err
+			}
+			break
+		}
+		for name, msg := range r.res.ProviderErrors {
+			if w.providerErrors == nil {
+				w.providerErrors = make(map[string]string)
+			}
+			w.providerErrors[name] = msg
+		}
+		if r.res.Stale {
+			w.stale = true
+			w.staleAge = max(w.staleAge, r.res.StaleAgeSeconds)
+		}
+		requested := min(up, size-i*up)
+		if len(r.res.Items) > requested {
+			// 要求より多く返す上流（横断検索など）でもページの件数とオフセットを崩さない
+			r.res.Items = r.res.Items[:requested]
+		}
+		for _, b := range r.res.Items {
+			if b.ID != "" && seen[b.ID] {
+				continue
+			}
+			seen[b.ID] = true
+			w.items = append(w.items, b)
+		}
+		w.total = max(w.total, r.res.TotalItems)
+		// 読み進めた位置。横断検索は重複をまとめた分も位置を進めるため NextStartIndex で報告する
+		consumed := len(r.res.Items)
+		if pageStart := start + i*up; r.res.NextStartIndex > pageStart {
+			consumed = min(r.res.NextStartIndex-pageStart, requested)
+		}
+		if consumed < requested {
+			// 短いページ = 上流の終端。totalItems は揺れるため実際に読めた位置を総件数とする
+			w.next += consumed
+			w.total = w.next
+			w.exhausted = true
+			w.end = w.next
+			if w.next == start && start > 0 {
+				// 読み始めから空なら終端はもっと前にある。上流の totalItems で見積もる
+				w.end = min(r.res.TotalItems, start)
+			}
+			break
+		}
+		w.next += requested
+	}
+	if w.total < w.next {
+		w.total = w.next
+	}
+	return w, nil
+}
@@ -0,0 +1,165 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/favorites"
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/tsundoku"
+)
+
+// ExcludeFavorites hides favorited books; the tsundoku statuses hide books in that state.
+const ExcludeFavorites = "favorites"
+
+// State is what the caller's library knows about one book.
+type State struct {
+	IsFavorite  bool            `json:"IsFavorite"`
+	FavoritedAt *time.Time      `json:"FavoritedAt,omitempty"`
+	Status      tsundoku.Status `json:"Status,omitempty"`
+	AddedAt     *time.Time      `json:"AddedAt,omitempty"`
+	StartedAt   *time.Time      `json:"StartedAt,omitempty"`
+	CompletedAt *time.Time      `json:"CompletedAt,omitempty"`
+}
+
+// Service combines favorites and tsundoku into per-book library state.
+type Service struct {
+	favorites *favorites.Service
+	tsundoku  *tsundoku.Service
+}
+
+// NewService creates a library service. Either dependency may be nil.
+func NewService(favoritesService *favorites.Service, tsundokuService *tsundoku.Service) *Service {
+	return &Service{favorites: favoritesService, tsundoku: tsundokuService}
+}
+
+// Snapshot is the library loaded once for one request.
+type Snapshot struct {
+	favorites map[string]favorites.Item
+	tsundoku  map[string]tsundoku.Item
+}
+
+// Snapshot loads the current favorites and tsundoku items.
+func (s *Service) Snapshot(ctx context.Context) (*Snapshot, error) {
+	snap := &Snapshot{
+		favorites: make(map[string]favorites.Item),
+		tsundoku:  make(map[string]tsundoku.Item),
+	}
+	if s.favorites != nil {
+		items, err := s.favorites.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list favorites: %w", err)
+		}
+		for _, it := range items {
+			for _, k := range keys(it.ID, it.Book) {
+				snap.favorites[k] = it
+			}
+		}
+	}
+	if s.tsundoku != nil {
+		items, err := s.tsundoku.List(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list tsundoku: %w", err)
+		}
+		for _, it := range items {
+			for _, k := range keys(it.ID, it.Book) {
+				snap.tsundoku[k] = it
+			}
+		}
+	}
+	return snap, nil
+}
+
+// keys indexes an entry by its book ID and, when known, its ISBN-13 so that
+// another edition or provider of the same book is recognized too.
+func keys(id string, b books.Book) []string {
+	out := []string{"id:" + id}
+	if b.ISBN13 != "" {
+		out = append(out, "isbn:"+b.ISBN13)
+	}
+	return out
+}
+
+func lookup[T any](m map[string]T, b books.Book) (T, bool) {
+	if v, ok := m["id:"+b.ID]; ok {
+		return v, true
+	}
+	if b.ISBN13 != "" {
+		if v, ok := m["isbn:"+b.ISBN13]; ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// State returns the library state of b, or nil when the book is in neither list.
+func (s *Snapshot) State(b books.Book) *State {
+	fav, isFav := lookup(s.favorites, b)
+	item, stacked := lookup(s.tsundoku, b)
+	if !isFav && !stacked {
+		return nil
+	}
+	st := &State{IsFavorite: isFav}
+	if isFav {
+		at := fav.AddedAt
+		st.FavoritedAt = &at
+	}
+	if stacked {
+		added := item.AddedAt
+		st.Status = item.Status
+		st.AddedAt = &added
+		st.StartedAt = item.StartedAt
+		st.CompletedAt = item.CompletedAt
+	}
+	return st
+}
+
+// ExclusionFilter loads a snapshot of the library and returns the filter for exclusions.
+func (s *Service) ExclusionFilter(ctx context.Context, exclusions []string) (books.Filter, error) {
+	snap, err := s.Snapshot(ctx)
+	if err != nil {
+		return books.Filter{}, err
+	}
+	return snap.Filter(exclusions), nil
+}
+
+// ParseExclusions validates a list of exclusions (favorites, stacked, reading, done).
+func ParseExclusions(values []string) ([]string, error) {
+	var out []string
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		if _, ok := tsundoku.ParseStatus(v); !ok && v != ExcludeFavorites {
+			return nil, fmt.Errorf("unknown library state %q", v)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Filter drops books that match any of the exclusions.
+func (s *Snapshot) Filter(exclusions []string) books.Filter {
+	return books.Filter{
+		Name: "library",
+		Keep: func(b books.Book) bool {
+			st := s.State(b)
+			if st == nil {
+				return true
+			}
+			for _, ex := range exclusions {
+				if ex == ExcludeFavorites && st.IsFavorite {
+					return false
+				}
+				if st.Status != "" && string(st.Status) == ex {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
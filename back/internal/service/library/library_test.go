@@ -0,0 +1,26 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExclusions(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{[]string{"done", "stacked", "favorites"}, []string{"done", "stacked", "favorites"}, false},
+		{[]string{"Done", " reading ", ""}, []string{"done", "reading"}, false},
+		{[]string{"done", "java"}, nil, true},
+		{[]string{"favorite"}, nil, true},
+		{nil, nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseExclusions(tt.values)
+		if !reflect.DeepEqual(got, tt.want) || (err != nil) != tt.wantErr {
+			t.Errorf("ParseExclusions(%q) = %q, %v; want %q (error %v)", tt.values, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
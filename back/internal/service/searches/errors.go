@@ -0,0 +1,14 @@
+package searches
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when a saved search or history entry does not exist.
+	ErrNotFound = errors.New("saved search not found")
+
+	// ErrAlreadyExists is returned when a saved search with the same name exists.
+	ErrAlreadyExists = errors.New("saved search already exists")
+
+	// ErrInvalidInput is returned when required fields are missing or malformed.
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,42 @@
+package searches
+
+import (
+	"context"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// Repository defines the data layer for search history and saved searches.
+type Repository interface {
+	// AppendHistory records a search, keeping at most limit entries (oldest dropped first).
+	AppendHistory(ctx context.Context, entry HistoryEntry, limit int) error
+
+	// ListHistory returns the history, newest first.
+	ListHistory(ctx context.Context) ([]HistoryEntry, error)
+
+	// ClearHistory removes every history entry.
+	ClearHistory(ctx context.Context) error
+
+	// GetSaved retrieves a saved search by ID.
+	GetSaved(ctx context.Context, id string) (SavedSearch, error)
+
+	// UpsertSaved creates or updates a saved search.
+	UpsertSaved(ctx context.Context, s SavedSearch) error
+
+	// DeleteSaved removes a saved search by ID.
+	DeleteSaved(ctx context.Context, id string) error
+
+	// ListSaved returns all saved searches.
+	ListSaved(ctx context.Context) ([]SavedSearch, error)
+}
+
+// Library builds the filter that hides books already in the caller's library;
+// *library.Service satisfies it.
+type Library interface {
+	ExclusionFilter(ctx context.Context, exclusions []string) (books.Filter, error)
+}
+
+// Searcher runs a book search; *books.Service satisfies it.
+type Searcher interface {
+	Search(ctx context.Context, params books.SearchParams) (books.SearchResult, error)
+}
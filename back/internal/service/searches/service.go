@@ -0,0 +1,325 @@
+package searches
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+const (
+	// DefaultHistoryLimit is the number of history entries kept by default.
+	DefaultHistoryLimit = 200
+	// MinRunEvery is the shortest accepted schedule.
+	MinRunEvery = time.Minute
+	// runPageSize is how many top results a run compares against earlier runs.
+	runPageSize = 40
+	// maxSeenIDs bounds the IDs remembered per saved search.
+	maxSeenIDs = 2000
+)
+
+// Service contains the application logic for search history and saved searches.
+type Service struct {
+	repo         Repository
+	searcher     Searcher
+	library      Library
+	now          func() time.Time
+	historyLimit int
+
+	// runs serializes runs and deletes, so a scheduled run and an on-demand run do not race on
+	// SeenIDs and a run cannot write back a saved search that was deleted while it was running.
+	runs sync.Mutex
+	// saves serializes saves so the unique name check and the write happen together.
+	saves sync.Mutex
+}
+
+// NewService creates a new searches service.
+func NewService(repo Repository, searcher Searcher) *Service {
+	return &Service{
+		repo:         repo,
+		searcher:     searcher,
+		now:          time.Now,
+		historyLimit: DefaultHistoryLimit,
+	}
+}
+
+// WithNow overrides the now function (primarily for testing).
+func (s *Service) WithNow(fn func() time.Time) {
+	if fn != nil {
+		s.now = fn
+	}
+}
+
+// WithLibrary lets runs apply the saved library exclusions (excludeLibrary=done etc.).
+// Without it those exclusions are ignored, as in the search handler.
+func (s *Service) WithLibrary(lib Library) {
+	s.library = lib
+}
+
+// WithHistoryLimit sets how many history entries are kept.
+func (s *Service) WithHistoryLimit(n int) {
+	if n > 0 {
+		s.historyLimit = n
+	}
+}
+
+// RecordSearch appends a search to the history.
+func (s *Service) RecordSearch(ctx context.Context, params books.SearchParams, totalItems int) error {
+	return s.repo.AppendHistory(ctx, HistoryEntry{
+		ID:         newID(),
+		Params:     params,
+		TotalItems: totalItems,
+		SearchedAt: s.now().UTC(),
+	}, s.historyLimit)
+}
+
+// History returns up to limit history entries, newest first (all when limit <= 0).
+func (s *Service) History(ctx context.Context, limit int) ([]HistoryEntry, error) {
+	entries, err := s.repo.ListHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// HistoryEntry returns one history entry by ID.
+func (s *Service) HistoryEntry(ctx context.Context, id string) (HistoryEntry, error) {
+	entries, err := s.repo.ListHistory(ctx)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return HistoryEntry{}, ErrNotFound
+}
+
+// ClearHistory removes every history entry.
+func (s *Service) ClearHistory(ctx context.Context) error {
+	return s.repo.ClearHistory(ctx)
+}
+
+// Save stores a search under a unique name.
+func (s *Service) Save(ctx context.Context, params SaveParams) (SavedSearch, error) {
+	name := strings.TrimSpace(params.Name)
+	if name == "" || !params.Params.HasCriteria() {
+		return SavedSearch{}, ErrInvalidInput
+	}
+	if params.RunEvery != 0 && params.RunEvery < MinRunEvery {
+		return SavedSearch{}, fmt.Errorf("%w: runEvery must be at least %s", ErrInvalidInput, MinRunEvery)
+	}
+
+	s.saves.Lock()
+	defer s.saves.Unlock()
+
+	existing, err := s.repo.ListSaved(ctx)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	for _, e := range existing {
+		if strings.EqualFold(e.Name, name) {
+			return SavedSearch{}, ErrAlreadyExists
+		}
+	}
+
+	// Saved searches always start from the first page.
+	p := params.Params
+	p.StartIndex = 0
+	p.SeenIDs = nil
+	p.ExtraFilters = nil
+
+	now := s.now().UTC()
+	saved := SavedSearch{
+		ID:        newID(),
+		Name:      name,
+		Params:    p,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if params.RunEvery > 0 {
+		saved.RunEvery = params.RunEvery.String()
+	}
+	if err := s.repo.UpsertSaved(ctx, saved); err != nil {
+		return SavedSearch{}, err
+	}
+	return saved, nil
+}
+
+// ListSaved returns all saved searches.
+func (s *Service) ListSaved(ctx context.Context) ([]SavedSearch, error) {
+	return s.repo.ListSaved(ctx)
+}
+
+// GetSaved returns a saved search by ID.
+func (s *Service) GetSaved(ctx context.Context, id string) (SavedSearch, error) {
+	if id == "" {
+		return SavedSearch{}, ErrInvalidInput
+	}
+	return s.repo.GetSaved(ctx, id)
+}
+
+// DeleteSaved removes a saved search by ID. It waits for a run of the same search to finish.
+func (s *Service) DeleteSaved(ctx context.Context, id string) error {
+	s.runs.Lock()
+	defer s.runs.Unlock()
+
+	if _, err := s.GetSaved(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSaved(ctx, id)
+}
+
+// Run executes a saved search and returns only the books no earlier run returned.
+// Only the first page (the saved page size, at most 40) is compared, which is where new
+// books show up with orderBy=newest.
+func (s *Service) Run(ctx context.Context, id string) (RunResult, error) {
+	s.runs.Lock()
+	defer s.runs.Unlock()
+
+	saved, err := s.GetSaved(ctx, id)
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	p := saved.Params
+	p.StartIndex = 0
+	if p.MaxResults <= 0 || p.MaxResults > runPageSize {
+		p.MaxResults = runPageSize
+	}
+	now := s.now().UTC()
+	res, err := s.search(ctx, p)
+	if err != nil {
+		saved.LastRun = &RunSummary{At: now, Error: err.Error()}
+		saved.UpdatedAt = now
+		if perr := s.repo.UpsertSaved(ctx, saved); perr != nil {
+			log.Printf("failed to record saved search failure: %v", perr)
+		}
+		return RunResult{}, err
+	}
+
+	seen := make(map[string]bool, len(saved.SeenIDs))
+	for _, id := range saved.SeenIDs {
+		seen[id] = true
+	}
+	// A failed run also sets LastRun, so an earlier successful run is told by the IDs it kept
+	// (or by a LastRun without an error when it found nothing).
+	firstRun := len(saved.SeenIDs) == 0 && (saved.LastRun == nil || saved.LastRun.Error != "")
+	result := RunResult{TotalItems: res.TotalItems, FirstRun: firstRun, NewItems: []books.Book{}}
+	var newIDs []string
+	for _, b := range res.Items {
+		ids := append([]string{b.ID}, b.AlternateEditionIDs...)
+		known := false
+		for _, id := range ids {
+			if seen[id] {
+				known = true
+			}
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				saved.SeenIDs = append(saved.SeenIDs, id)
+			}
+		}
+		if !known {
+			result.NewItems = append(result.NewItems, b)
+			newIDs = append(newIDs, b.ID)
+		}
+	}
+	if len(saved.SeenIDs) > maxSeenIDs {
+		saved.SeenIDs = saved.SeenIDs[len(saved.SeenIDs)-maxSeenIDs:]
+	}
+	saved.LastRun = &RunSummary{At: now, TotalItems: res.TotalItems, NewIDs: newIDs}
+	saved.UpdatedAt = now
+	if err := s.repo.UpsertSaved(ctx, saved); err != nil {
+		return RunResult{}, err
+	}
+	result.Search = saved
+	return result, nil
+}
+
+// search runs p with the library exclusions rebuilt from the current library, so books
+// added to it since the search was saved are not reported as new.
+func (s *Service) search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	if len(p.ExcludeLibrary) > 0 && s.library != nil {
+		filter, err := s.library.ExclusionFilter(ctx, p.ExcludeLibrary)
+		if err != nil {
+			return books.SearchResult{}, err
+		}
+		p.ExtraFilters = append(p.ExtraFilters, filter)
+	}
+	return s.searcher.Search(ctx, p)
+}
+
+// RunDue runs every scheduled search whose interval has elapsed and returns how many ran.
+func (s *Service) RunDue(ctx context.Context) (int, error) {
+	saved, err := s.repo.ListSaved(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ran := 0
+	for _, ss := range saved {
+		if !s.due(ss) {
+			continue
+		}
+		res, err := s.Run(ctx, ss.ID)
+		if ctx.Err() != nil {
+			return ran, ctx.Err()
+		}
+		ran++
+		if err != nil {
+			log.Printf("scheduled search %q failed: %v", ss.Name, err)
+			continue
+		}
+		if len(res.NewItems) > 0 {
+			log.Printf("scheduled search %q found %d new books", ss.Name, len(res.NewItems))
+		}
+	}
+	return ran, nil
+}
+
+func (s *Service) due(ss SavedSearch) bool {
+	if ss.RunEvery == "" {
+		return false
+	}
+	every, err := time.ParseDuration(ss.RunEvery)
+	if err != nil || every <= 0 {
+		return false
+	}
+	if ss.LastRun == nil {
+		return true
+	}
+	return !s.now().Before(ss.LastRun.At.Add(every))
+}
+
+// RunScheduler checks for due saved searches every tick until ctx is canceled.
+func (s *Service) RunScheduler(ctx context.Context, tick time.Duration) {
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("saved search scheduler: %v", err)
+			}
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,380 @@
+package searches
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// memRepo is an in-memory Repository.
+type memRepo struct {
+	mu      sync.Mutex
+	history []HistoryEntry
+	saved   map[string]SavedSearch
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{saved: make(map[string]SavedSearch)}
+}
+
+func (r *memRepo) AppendHistory(ctx context.Context, e HistoryEntry, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = append([]HistoryEntry{e}, r.history...)
+	if len(r.history) > limit {
+		r.history = r.history[:limit]
+	}
+	return nil
+}
+
+func (r *memRepo) ListHistory(ctx context.Context) ([]HistoryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]HistoryEntry(nil), r.history...), nil
+}
+
+func (r *memRepo) ClearHistory(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = nil
+	return nil
+}
+
+func (r *memRepo) GetSaved(ctx context.Context, id string) (SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.saved[id]
+	if !ok {
+		return SavedSearch{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *memRepo) UpsertSaved(ctx context.Context, s SavedSearch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved[s.ID] = s
+	return nil
+}
+
+func (r *memRepo) DeleteSaved(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.saved, id)
+	return nil
+}
+
+func (r *memRepo) ListSaved(ctx context.Context) ([]SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]SavedSearch, 0, len(r.saved))
+	for _, s := range r.saved {
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// stubSearcher returns the books with the given IDs for every search, or err when set.
+type stubSearcher struct {
+	mu    sync.Mutex
+	ids   []string
+	err   error
+	calls int
+}
+
+func (s *stubSearcher) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return books.SearchResult{}, s.err
+	}
+	res := books.SearchResult{TotalItems: len(s.ids)}
+	for _, id := range s.ids {
+		b := books.Book{ID: id}
+		keep := true
+		for _, f := range p.ExtraFilters {
+			keep = keep && f.Keep(b)
+		}
+		if keep {
+			res.Items = append(res.Items, b)
+		}
+	}
+	return res, nil
+}
+
+// stubLibrary treats the given IDs as favorites.
+type stubLibrary struct {
+	mu        sync.Mutex
+	favorites map[string]bool
+}
+
+func (l *stubLibrary) ExclusionFilter(ctx context.Context, exclusions []string) (books.Filter, error) {
+	l.mu.Lock()
+	owned := make(map[string]bool, len(l.favorites))
+	for id := range l.favorites {
+		owned[id] = true
+	}
+	l.mu.Unlock()
+	return books.Filter{Name: "library", Keep: func(b books.Book) bool { return !owned[b.ID] }}, nil
+}
+
+func (s *stubSearcher) setIDs(ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids = ids
+}
+
+func (s *stubSearcher) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// blockingSearcher signals entered when a search starts and returns once release is closed.
+type blockingSearcher struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSearcher) Search(ctx context.Context, p books.SearchParams) (books.SearchResult, error) {
+	close(s.entered)
+	<-s.release
+	return books.SearchResult{TotalItems: 1, Items: []books.Book{{ID: "a"}}}, nil
+}
+
+func newItemIDs(res RunResult) []string {
+	ids := []string{}
+	for _, b := range res.NewItems {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
+
+func TestRunReportsOnlyNewBooks(t *testing.T) {
+	searcher := &stubSearcher{ids: []string{"a", "b"}}
+	s := NewService(newMemRepo(), searcher)
+	ctx := context.Background()
+
+	saved, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "golang"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.FirstRun || len(first.NewItems) != 2 {
+		t.Fatalf("first run: %+v", first)
+	}
+
+	searcher.setIDs("c", "a", "b")
+	second, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := newItemIDs(second); second.FirstRun || len(got) != 1 || got[0] != "c" {
+		t.Fatalf("second run: new items %v, firstRun %v", got, second.FirstRun)
+	}
+}
+
+func TestRunDueFollowsSchedule(t *testing.T) {
+	searcher := &stubSearcher{ids: []string{"a"}}
+	s := NewService(newMemRepo(), searcher)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.WithNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	if _, err := s.Save(ctx, SaveParams{Name: "daily", Params: books.SearchParams{Query: "go"}, RunEvery: 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(ctx, SaveParams{Name: "on demand", Params: books.SearchParams{Query: "rust"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		advance time.Duration
+		wantRan int
+	}{
+		{0, 1},              // never ran
+		{time.Hour, 0},      // not due yet
+		{23 * time.Hour, 1}, // 24h after the first run
+		{time.Minute, 0},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		ran, err := s.RunDue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ran != step.wantRan {
+			t.Fatalf("step %d: ran = %d, want %d", i, ran, step.wantRan)
+		}
+	}
+	if searcher.calls != 2 {
+		t.Fatalf("searches = %d, want 2", searcher.calls)
+	}
+}
+
+func TestSaveValidatesInput(t *testing.T) {
+	s := NewService(newMemRepo(), &stubSearcher{})
+	ctx := context.Background()
+	cases := []SaveParams{
+		{Name: "", Params: books.SearchParams{Query: "go"}},
+		{Name: "no criteria"},
+		{Name: "too often", Params: books.SearchParams{Query: "go"}, RunEvery: time.Second},
+	}
+	for _, c := range cases {
+		if _, err := s.Save(ctx, c); err == nil {
+			t.Errorf("Save(%+v) succeeded", c)
+		}
+	}
+	if _, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(ctx, SaveParams{Name: "go", Params: books.SearchParams{Query: "golang"}}); err != ErrAlreadyExists {
+		t.Fatalf("duplicate name: err = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func TestRunAppliesLibraryExclusions(t *testing.T) {
+	searcher := &stubSearcher{ids: []string{"a", "b"}}
+	lib := &stubLibrary{favorites: map[string]bool{"a": true}}
+	s := NewService(newMemRepo(), searcher)
+	s.WithLibrary(lib)
+	ctx := context.Background()
+
+	saved, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "golang", ExcludeLibrary: []string{"favorites"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := newItemIDs(first); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("first run: new items %v, want [b]", got)
+	}
+
+	// c is new but was favorited before this run, so it is not reported
+	searcher.setIDs("c", "a", "b")
+	lib.mu.Lock()
+	lib.favorites["c"] = true
+	lib.mu.Unlock()
+	second, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := newItemIDs(second); len(got) != 0 {
+		t.Fatalf("second run: new items %v, want none", got)
+	}
+}
+
+func TestConcurrentSavesKeepNamesUnique(t *testing.T) {
+	repo := newMemRepo()
+	s := NewService(repo, &stubSearcher{})
+	ctx := context.Background()
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "golang"}}); err == nil {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	saved, _ := repo.ListSaved(ctx)
+	if ok != 1 || len(saved) != 1 {
+		t.Fatalf("%d saves succeeded and %d searches stored, want 1", ok, len(saved))
+	}
+}
+
+func TestFailedRunDoesNotEndFirstRun(t *testing.T) {
+	searcher := &stubSearcher{ids: []string{"a", "b"}, err: books.ErrUpstreamUnavailable}
+	s := NewService(newMemRepo(), searcher)
+	ctx := context.Background()
+
+	saved, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "golang"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Run(ctx, saved.ID); !errors.Is(err, books.ErrUpstreamUnavailable) {
+		t.Fatalf("failing run: err = %v", err)
+	}
+	if got, _ := s.GetSaved(ctx, saved.ID); got.LastRun == nil || got.LastRun.Error == "" {
+		t.Fatalf("failure was not recorded: %+v", got.LastRun)
+	}
+
+	searcher.setErr(nil)
+	first, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.FirstRun || len(first.NewItems) != 2 {
+		t.Fatalf("first successful run: firstRun %v, new items %v", first.FirstRun, newItemIDs(first))
+	}
+
+	// a failure between two successful runs does not make the next run a first run again
+	searcher.setErr(books.ErrUpstreamUnavailable)
+	if _, err := s.Run(ctx, saved.ID); err == nil {
+		t.Fatal("failing run succeeded")
+	}
+	searcher.setErr(nil)
+	searcher.setIDs("c", "a", "b")
+	next, err := s.Run(ctx, saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := newItemIDs(next); next.FirstRun || len(got) != 1 || got[0] != "c" {
+		t.Fatalf("run after a failure: firstRun %v, new items %v", next.FirstRun, got)
+	}
+}
+
+func TestDeleteDuringRunIsNotUndone(t *testing.T) {
+	searcher := &blockingSearcher{entered: make(chan struct{}), release: make(chan struct{})}
+	s := NewService(newMemRepo(), searcher)
+	ctx := context.Background()
+
+	saved, err := s.Save(ctx, SaveParams{Name: "Go", Params: books.SearchParams{Query: "golang"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runDone := make(chan error, 1)
+	go func() {
+		_, err := s.Run(ctx, saved.ID)
+		runDone <- err
+	}()
+	<-searcher.entered
+
+	deleteDone := make(chan error, 1)
+	go func() { deleteDone <- s.DeleteSaved(ctx, saved.ID) }()
+	select {
+	case err := <-deleteDone:
+		t.Fatalf("delete finished during the run: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(searcher.release)
+	if err := <-runDone; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if err := <-deleteDone; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.GetSaved(ctx, saved.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("saved search after delete: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,54 @@
+package searches
+
+import (
+	"time"
+
+	"github.com/recursion-goapi-project/technical-books-search/back/internal/service/books"
+)
+
+// HistoryEntry is one search that was run through the search endpoint.
+type HistoryEntry struct {
+	ID         string             `json:"ID"`
+	Params     books.SearchParams `json:"Params"`
+	TotalItems int                `json:"TotalItems"`
+	SearchedAt time.Time          `json:"SearchedAt"`
+}
+
+// SavedSearch is a named search that can be re-run to find new results.
+type SavedSearch struct {
+	ID     string             `json:"ID"`
+	Name   string             `json:"Name"`
+	Params books.SearchParams `json:"Params"`
+	// RunEvery is the schedule as a Go duration (e.g. "24h"); empty means on demand only.
+	RunEvery  string      `json:"RunEvery,omitempty"`
+	CreatedAt time.Time   `json:"CreatedAt"`
+	UpdatedAt time.Time   `json:"UpdatedAt"`
+	LastRun   *RunSummary `json:"LastRun,omitempty"`
+	// SeenIDs are the volume IDs returned by earlier runs.
+	SeenIDs []string `json:"SeenIDs,omitempty"`
+}
+
+// RunSummary records the outcome of the latest run.
+type RunSummary struct {
+	At         time.Time `json:"At"`
+	TotalItems int       `json:"TotalItems"`
+	NewIDs     []string  `json:"NewIDs,omitempty"`
+	Error      string    `json:"Error,omitempty"`
+}
+
+// SaveParams is the input for saving a search.
+type SaveParams struct {
+	Name     string
+	Params   books.SearchParams
+	RunEvery time.Duration
+}
+
+// RunResult is the outcome of running a saved search.
+type RunResult struct {
+	Search SavedSearch `json:"Search"`
+	// NewItems are the books whose IDs no earlier run returned.
+	NewItems   []books.Book `json:"NewItems"`
+	TotalItems int          `json:"TotalItems"`
+	// FirstRun is true when there was no earlier run, so every result counts as new.
+	FirstRun bool `json:"FirstRun"`
+}
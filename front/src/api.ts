@@ -1,4 +1,4 @@
-import type { Book, SearchResponse, TsundokuItem, TsundokuStatus, FavoriteItem } from './types';
+import type { Book, SearchResponse, TechTag, TsundokuItem, TsundokuStatus, FavoriteItem } from './types';
 
 /**
  * Parameters for searching books via Google Books API
@@ -9,6 +9,7 @@ export type SearchParams = {
   startIndex?: number;
   orderBy?: 'relevance' | 'newest';
   lang?: string;
+  tags?: string[];
 };
 
 /**
@@ -44,6 +45,7 @@ async function parseResponse<T>(res: Response): Promise<T> {
 export async function searchBooks(params: SearchParams): Promise<SearchResponse> {
   const usp = new URLSearchParams();
   if (params.q) usp.set('q', params.q);
+  if (params.tags && params.tags.length > 0) usp.set('tags', params.tags.join(','));
   usp.set('page', String(params.page ?? 1));
   if (typeof params.startIndex === 'number') {
     usp.set('startIndex', String(params.startIndex));
@@ -55,6 +57,14 @@ export async function searchBooks(params: SearchParams): Promise<SearchResponse>
   return parseResponse<SearchResponse>(res);
 }
 
+/**
+ * Fetch the technology tags usable with the tags search parameter
+ */
+export async function fetchTags(): Promise<TechTag[]> {
+  const res = await fetch('/api/tags');
+  return parseResponse<TechTag[]>(res);
+}
+
 // ============================================================================
 // Tsundoku (Reading List) API
 // ============================================================================
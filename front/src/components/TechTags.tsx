@@ -1,4 +1,6 @@
-import { TECH_TAGS } from '../tags';
+import { useEffect, useState } from 'react';
+import { fetchTags } from '../api';
+import type { TechTag } from '../types';
 
 type Props = {
   selected: string[];
@@ -6,16 +8,29 @@ type Props = {
 };
 
 export default function TechTags({ selected, onToggle }: Props) {
+  const [tags, setTags] = useState<TechTag[]>([]);
+  const [error, setError] = useState<string>('');
+
+  // タグの定義はバックエンド（GET /api/tags）が持つ
+  useEffect(() => {
+    let cancelled = false;
+    fetchTags()
+      .then((data) => { if (!cancelled) setTags(Array.isArray(data) ? data : []); })
+      .catch((e: any) => { if (!cancelled) setError(e?.message || 'failed to load tags'); });
+    return () => { cancelled = true; };
+  }, []);
+
   return (
     <div>
       <label style={{ fontSize: 13, color: '#475569', fontWeight: 600, marginBottom: 12, display: 'block' }}>🏷️ Technology Tags</label>
       <div style={{ display: 'flex', gap: 10, flexWrap: 'wrap' }}>
-        {TECH_TAGS.map((t) => {
-          const active = selected.includes(t.key);
+        {error && <span style={{ fontSize: 13, color: '#ef4444' }}>{error}</span>}
+        {tags.map((t) => {
+          const active = selected.includes(t.Key);
           return (
             <button
-              key={t.key}
-              onClick={(e) => { e.preventDefault(); onToggle(t.key); }}
+              key={t.Key}
+              onClick={(e) => { e.preventDefault(); onToggle(t.Key); }}
               style={{
                 padding: '10px 20px',
                 borderRadius: 999,
@@ -43,7 +58,7 @@ export default function TechTags({ selected, onToggle }: Props) {
                 }
               }}
             >
-              {t.label}
+              {t.Label}
             </button>
           );
         })}
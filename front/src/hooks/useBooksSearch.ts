@@ -1,6 +1,5 @@
 import { useCallback, useEffect, useMemo, useState } from 'react';
 import { searchBooks, type SearchParams } from '../api';
-import { getQueriesFor } from '../tags';
 import type { Book } from '../types';
 
 const PAGE_SIZE = 10;
@@ -25,11 +24,7 @@ export function useBooksSearch(initial?: SearchParams) {
 
   const fetchData = useCallback(async () => {
     // 空クエリの場合はリクエストを送らない
-    const tagTerms = getQueriesFor(tagKeys);
-    const orExpr = tagTerms.length > 0
-      ? `(${tagTerms.map((t) => (t.includes(' ') ? `"${t}"` : t)).join(' OR ')})`
-      : '';
-
+    // タグの OR 展開はバックエンド（tags= パラメータ）に任せる
     const free = q.trim();
     const tokenExprs = free === ''
       ? []
@@ -39,14 +34,14 @@ export function useBooksSearch(initial?: SearchParams) {
           return `(intitle:${quoted} OR "${w}")`;
         });
 
-    const finalQ = [orExpr, ...tokenExprs].filter(Boolean).join(' ');
-    if (finalQ === '') return;
+    const finalQ = tokenExprs.join(' ');
+    if (finalQ === '' && tagKeys.length === 0) return;
 
     setLoading(true);
     setError('');
     try {
       const startIndex = (page - 1) * PAGE_SIZE;
-      const data = await searchBooks({ q: finalQ, page, startIndex, orderBy, lang });
+      const data = await searchBooks({ q: finalQ, tags: tagKeys, page, startIndex, orderBy, lang });
       setItems(Array.isArray(data.Items) ? data.Items : []);
       setTotal(typeof data.TotalItems === 'number' ? data.TotalItems : 0);
     } catch (e: any) {
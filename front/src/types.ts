@@ -1,3 +1,8 @@
+export type Price = {
+  Amount: number;
+  CurrencyCode: string;
+};
+
 export type Book = {
   ID: string;
   Title: string;
@@ -8,11 +13,74 @@ export type Book = {
   PageCount?: number;
   Thumbnail?: string;
   InfoLink?: string;
+  ISBN10?: string;
+  ISBN13?: string;
+  Subtitle?: string;
+  Publisher?: string;
+  Language?: string;
+  AverageRating?: number;
+  RatingsCount?: number;
+  PreviewLink?: string;
+  Images?: {
+    SmallThumbnail?: string;
+    Thumbnail?: string;
+    Small?: string;
+    Medium?: string;
+    Large?: string;
+    ExtraLarge?: string;
+  };
+  TextSnippet?: string;
+  Sale?: {
+    Country?: string;
+    Saleability?: 'FOR_SALE' | 'FREE' | 'NOT_FOR_SALE' | 'FOR_PREORDER';
+    IsEbook?: boolean;
+    ListPrice?: Price;
+    RetailPrice?: Price;
+    BuyLink?: string;
+  };
+  Access?: {
+    Viewability?: 'NO_PAGES' | 'PARTIAL' | 'ALL_PAGES';
+    PublicDomain?: boolean;
+    EpubAvailable?: boolean;
+    PdfAvailable?: boolean;
+    EpubDownloadLink?: string;
+    PdfDownloadLink?: string;
+    WebReaderLink?: string;
+  };
+  Published?: {
+    Year: number;
+    Month?: number;
+    Day?: number;
+    Precision: 'year' | 'month' | 'day';
+  };
+  AlternateEditionIDs?: string[];
+  Provider?: string;
+  Sources?: Record<string, string>;
+  Library?: LibraryState;
+};
+
+export type LibraryState = {
+  IsFavorite: boolean;
+  FavoritedAt?: string;
+  Status?: TsundokuStatus;
+  AddedAt?: string;
+  StartedAt?: string;
+  CompletedAt?: string;
 };
 
 export type SearchResponse = {
   TotalItems: number;
   Items: Book[];
+  Stale?: boolean;
+  StaleAgeSeconds?: number;
+  LanguageTotals?: Record<string, number>;
+};
+
+// Technology tag served by GET /api/tags; Queries are OR-ed by the backend
+export type TechTag = {
+  Key: string;
+  Label: string;
+  Queries: string[];
 };
 
 export type TsundokuStatus = 'stacked' | 'reading' | 'done';